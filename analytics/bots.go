@@ -0,0 +1,156 @@
+package analytics
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Bot categories used in the rules file
+const (
+	BotUnfurler = "unfurler"
+	BotCrawler  = "crawler"
+	BotMonitor  = "monitor"
+	BotTool     = "tool"
+	BotOther    = "other"
+)
+
+//go:embed bot_rules.txt
+var defaultBotRules string
+
+// BotRule marks user agents containing Pattern as the named bot.
+type BotRule struct {
+	Category string
+	Name     string
+	Pattern  string
+}
+
+// BotMatch describes the bot a user agent belongs to.
+type BotMatch struct {
+	Category string
+	Name     string
+}
+
+// BotClassifier tells automated clients apart from people by their user agent.
+type BotClassifier struct {
+	rules []BotRule
+}
+
+// ParseBotRules reads rules in the "category | name | pattern" format of bot_rules.txt.
+func ParseBotRules(r io.Reader) (*BotClassifier, error) {
+	classifier := &BotClassifier{}
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "|")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected \"category | name | pattern\"", lineNumber)
+		}
+
+		rule := BotRule{
+			Category: strings.TrimSpace(fields[0]),
+			Name:     strings.TrimSpace(fields[1]),
+			Pattern:  strings.ToLower(strings.TrimSpace(fields[2])),
+		}
+		if rule.Category == "" || rule.Name == "" || rule.Pattern == "" {
+			return nil, fmt.Errorf("line %d: category, name and pattern are required", lineNumber)
+		}
+
+		classifier.rules = append(classifier.rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return classifier, nil
+}
+
+// Classify returns the first rule matching the user agent. Requests without a
+// user agent are treated as bots since browsers always send one.
+func (b *BotClassifier) Classify(userAgent string) (BotMatch, bool) {
+	userAgent = strings.ToLower(strings.TrimSpace(userAgent))
+	if userAgent == "" {
+		return BotMatch{Category: BotOther, Name: "No user agent"}, true
+	}
+
+	for _, rule := range b.rules {
+		if strings.Contains(userAgent, rule.Pattern) {
+			return BotMatch{Category: rule.Category, Name: rule.Name}, true
+		}
+	}
+	return BotMatch{}, false
+}
+
+var (
+	botsOnce sync.Once
+	bots     *BotClassifier
+)
+
+// ClassifyBot classifies a user agent with the rules in BOT_RULES_FILE, or the bundled
+// rules when it is not set or cannot be read.
+func ClassifyBot(userAgent string) (BotMatch, bool) {
+	botsOnce.Do(loadBotRules)
+	return bots.Classify(userAgent)
+}
+
+func loadBotRules() {
+	if path := initializers.BotRulesFile(); path != "" {
+		file, err := os.Open(path)
+		if err == nil {
+			defer file.Close()
+			if bots, err = ParseBotRules(file); err == nil {
+				return
+			}
+		}
+		log.Printf("Failed to load bot rules from %s, using bundled rules: %v", path, err)
+	}
+
+	var err error
+	if bots, err = ParseBotRules(strings.NewReader(defaultBotRules)); err != nil {
+		log.Fatal("Invalid bundled bot rules:", err)
+	}
+}
+
+// RecordBotHit counts a bot redirect against the link, separately from its clicks.
+func RecordBotHit(linkID uint, bot BotMatch, now time.Time) error {
+	return initializers.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "link_id"}, {Name: "name"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"hits":         gorm.Expr("link_bot_hits.hits + 1"),
+				"category":     bot.Category,
+				"last_seen_at": now,
+			}),
+		}).Create(&models.LinkBotHit{
+			LinkID:     linkID,
+			Name:       bot.Name,
+			Category:   bot.Category,
+			Hits:       1,
+			LastSeenAt: now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Link{}).Where("id = ?", linkID).
+			UpdateColumn("bot_clicks", gorm.Expr("bot_clicks + 1")).Error
+	})
+}
@@ -0,0 +1,137 @@
+package analytics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBotRules(t *testing.T) {
+	rules := `# comment
+
+	   # indented comment
+crawler | Example Bot | ExampleBot
+  tool|Fetcher|fetcher/   
+`
+	classifier, err := ParseBotRules(strings.NewReader(rules))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []BotRule{
+		{Category: "crawler", Name: "Example Bot", Pattern: "examplebot"},
+		{Category: "tool", Name: "Fetcher", Pattern: "fetcher/"},
+	}
+	if len(classifier.rules) != len(want) {
+		t.Fatalf("parsed %d rules, want %d: %+v", len(classifier.rules), len(want), classifier.rules)
+	}
+	for i, rule := range classifier.rules {
+		if rule != want[i] {
+			t.Errorf("rule %d = %+v, want %+v", i, rule, want[i])
+		}
+	}
+}
+
+func TestParseBotRulesRejectsMalformedLines(t *testing.T) {
+	tests := []struct {
+		rules string
+		line  string
+	}{
+		{"crawler | Example", "line 1:"},
+		{"# ok\ncrawler | Example | example | extra", "line 2:"},
+		{"crawler | Example | example\n\n | Example | example", "line 3:"},
+		{"crawler |  | example", "line 1:"},
+		{"crawler | Example |   ", "line 1:"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseBotRules(strings.NewReader(tt.rules))
+		if err == nil {
+			t.Errorf("%q: parsed without error", tt.rules)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), tt.line) {
+			t.Errorf("%q: error %q does not point at %s", tt.rules, err, strings.TrimSuffix(tt.line, ":"))
+		}
+	}
+}
+
+func bundledBotClassifier(t *testing.T) *BotClassifier {
+	t.Helper()
+	classifier, err := ParseBotRules(strings.NewReader(defaultBotRules))
+	if err != nil {
+		t.Fatalf("bundled bot_rules.txt: %v", err)
+	}
+	return classifier
+}
+
+func TestClassifyBots(t *testing.T) {
+	classifier := bundledBotClassifier(t)
+
+	tests := []struct {
+		userAgent string
+		want      BotMatch
+	}{
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", BotMatch{BotCrawler, "Google"}},
+		{"Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm) Chrome/116.0.1938.76 Safari/537.36", BotMatch{BotCrawler, "Bing"}},
+		{"Mozilla/5.0 (compatible; YandexBot/3.0; +http://yandex.com/bots)", BotMatch{BotCrawler, "Yandex"}},
+		{"Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.0; +https://openai.com/gptbot)", BotMatch{BotCrawler, "GPTBot"}},
+		{"Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", BotMatch{BotUnfurler, "Slack"}},
+		{"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", BotMatch{BotUnfurler, "Facebook"}},
+		{"Twitterbot/1.0", BotMatch{BotUnfurler, "Twitter"}},
+		{"Mozilla/5.0 (compatible; Discordbot/2.0; +https://discordapp.com)", BotMatch{BotUnfurler, "Discord"}},
+		{"WhatsApp/2.23.20.0 A", BotMatch{BotUnfurler, "WhatsApp"}},
+		{"TelegramBot (like TwitterBot)", BotMatch{BotUnfurler, "Telegram"}},
+		{"Mozilla/5.0+(compatible; UptimeRobot/2.0; http://www.uptimerobot.com/)", BotMatch{BotMonitor, "UptimeRobot"}},
+		{"curl/8.4.0", BotMatch{BotTool, "curl"}},
+		{"Wget/1.21.4", BotMatch{BotTool, "Wget"}},
+		{"python-requests/2.31.0", BotMatch{BotTool, "Python"}},
+		{"Go-http-client/2.0", BotMatch{BotTool, "Go"}},
+		{"PostmanRuntime/7.36.0", BotMatch{BotTool, "PostmanRuntime"}},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/120.0.0.0 Safari/537.36", BotMatch{BotOther, "Headless Chrome"}},
+		{"SomeNewCrawler/0.1", BotMatch{BotOther, "Other bot"}},
+		// Matching ignores case
+		{"GOOGLEBOT", BotMatch{BotCrawler, "Google"}},
+		{"CURL/7.0", BotMatch{BotTool, "curl"}},
+		// Browsers always send a user agent
+		{"", BotMatch{BotOther, "No user agent"}},
+		{"   ", BotMatch{BotOther, "No user agent"}},
+	}
+
+	for _, tt := range tests {
+		got, ok := classifier.Classify(tt.userAgent)
+		if !ok {
+			t.Errorf("%q: not classified as a bot", tt.userAgent)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %+v, want %+v", tt.userAgent, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyBrowsers(t *testing.T) {
+	classifier := bundledBotClassifier(t)
+
+	browsers := []string{
+		// Desktop
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+		"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+		// Mobile
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Mobile Safari/537.36",
+		"Mozilla/5.0 (Linux; Android 13; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Mobile Safari/537.36",
+		// In-app browsers of apps whose preview fetchers are bots
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 [FBAN/FBIOS;FBAV/445.0.0.35.117;FBBV/553446453]",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 Instagram 312.0.2.18.120",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 Twitter for iPhone/10.20",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 LinkedInApp/9.29.6",
+	}
+
+	for _, userAgent := range browsers {
+		if match, ok := classifier.Classify(userAgent); ok {
+			t.Errorf("%q: classified as %+v", userAgent, match)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package analytics
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordClick adds a click to the link's daily, referrer and country totals.
+// The referrer is reduced to its hostname; country is an ISO 3166-1 alpha-2 code or empty.
+func RecordClick(linkID uint, referrer string, country string, now time.Time) error {
+	day := now.UTC().Format(dayLayout)
+	referrer = ReferrerHost(referrer)
+	country = normalizeCountry(country)
+
+	return initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := incrementClicks(tx, &models.LinkClickDay{LinkID: linkID, Day: day, Clicks: 1}, "link_click_days", "day"); err != nil {
+			return err
+		}
+		if err := incrementClicks(tx, &models.LinkReferrer{LinkID: linkID, Referrer: referrer, Clicks: 1}, "link_referrers", "referrer"); err != nil {
+			return err
+		}
+		return incrementClicks(tx, &models.LinkCountry{LinkID: linkID, Country: country, Clicks: 1}, "link_countries", "country")
+	})
+}
+
+// ReferrerHost reduces a Referer header to a hostname without "www.", or "" for direct visits.
+func ReferrerHost(referrer string) string {
+	parsed, err := url.Parse(strings.TrimSpace(referrer))
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// Helper function: Insert a rollup row with one click, or add one to the existing row
+func incrementClicks(tx *gorm.DB, row interface{}, table string, keyColumn string) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "link_id"}, {Name: keyColumn}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"clicks": gorm.Expr(table + ".clicks + 1")}),
+	}).Create(row).Error
+}
+
+// Helper function: Accept only two-letter country codes; CDNs use XX or T1 for unknown and Tor
+func normalizeCountry(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if len(country) != 2 || country == "XX" || country == "T1" {
+		return ""
+	}
+	for _, r := range country {
+		if r < 'A' || r > 'Z' {
+			return ""
+		}
+	}
+	return country
+}
@@ -0,0 +1,82 @@
+package analytics
+
+import (
+	"math"
+	"math/bits"
+)
+
+// hllPrecision sets the number of registers (2^12 = 4096 bytes per sketch) for a
+// standard error of about 1.6%.
+const hllPrecision = 12
+
+const hllRegisters = 1 << hllPrecision
+
+// HyperLogLog estimates the number of distinct 64-bit hashes added to it
+// using a fixed amount of memory.
+type HyperLogLog struct {
+	registers []byte
+}
+
+// NewHyperLogLog creates an empty sketch.
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{registers: make([]byte, hllRegisters)}
+}
+
+// HyperLogLogFromBytes restores a sketch saved with Bytes. Input of the wrong size yields an empty sketch.
+func HyperLogLogFromBytes(data []byte) *HyperLogLog {
+	h := NewHyperLogLog()
+	if len(data) == hllRegisters {
+		copy(h.registers, data)
+	}
+	return h
+}
+
+// Bytes returns the sketch registers for storage.
+func (h *HyperLogLog) Bytes() []byte {
+	return h.registers
+}
+
+// Add records a hash and reports whether the sketch changed. Hashes must be uniformly distributed.
+func (h *HyperLogLog) Add(hash uint64) bool {
+	index := hash >> (64 - hllPrecision)
+	// Guard bit so the rank is bounded when the remaining bits are all zero
+	rank := byte(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rank <= h.registers[index] {
+		return false
+	}
+	h.registers[index] = rank
+	return true
+}
+
+// Merge adds every hash recorded in other, as if they had been added to h directly.
+func (h *HyperLogLog) Merge(other *HyperLogLog) {
+	for i, register := range other.registers {
+		if register > h.registers[i] {
+			h.registers[i] = register
+		}
+	}
+}
+
+// Estimate returns the approximate number of distinct hashes added.
+func (h *HyperLogLog) Estimate() int {
+	m := float64(hllRegisters)
+
+	sum := 0.0
+	zeros := 0
+	for _, register := range h.registers {
+		sum += math.Ldexp(1, -int(register))
+		if register == 0 {
+			zeros++
+		}
+	}
+
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+
+	// Small cardinalities are more accurate with linear counting
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return int(math.Round(estimate))
+}
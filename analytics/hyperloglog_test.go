@@ -0,0 +1,100 @@
+package analytics
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// testHash spreads consecutive numbers uniformly over 64 bits (SplitMix64), like the SHA-256
+// based visitor hashes do.
+func testHash(i uint64) uint64 {
+	z := i + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func sketchOf(from uint64, to uint64) *HyperLogLog {
+	hll := NewHyperLogLog()
+	for i := from; i < to; i++ {
+		hll.Add(testHash(i))
+	}
+	return hll
+}
+
+func relativeError(estimate int, actual int) float64 {
+	return math.Abs(float64(estimate)-float64(actual)) / float64(actual)
+}
+
+func TestHyperLogLogEstimate(t *testing.T) {
+	if estimate := NewHyperLogLog().Estimate(); estimate != 0 {
+		t.Fatalf("empty sketch estimates %d", estimate)
+	}
+
+	// The standard error is about 1.6%; allow three times that
+	for _, cardinality := range []int{10, 100, 1000, 10000, 20000, 100000, 1000000} {
+		estimate := sketchOf(0, uint64(cardinality)).Estimate()
+		if err := relativeError(estimate, cardinality); err > 0.05 {
+			t.Errorf("%d distinct hashes estimated as %d (%.1f%% off)", cardinality, estimate, err*100)
+		}
+	}
+}
+
+func TestHyperLogLogIgnoresDuplicates(t *testing.T) {
+	hll := sketchOf(0, 5000)
+	before := hll.Estimate()
+
+	for i := uint64(0); i < 5000; i++ {
+		if hll.Add(testHash(i)) {
+			t.Fatalf("adding hash %d again changed the sketch", i)
+		}
+	}
+	if after := hll.Estimate(); after != before {
+		t.Fatalf("estimate went from %d to %d after adding duplicates", before, after)
+	}
+}
+
+func TestHyperLogLogBytesRoundTrip(t *testing.T) {
+	original := sketchOf(0, 20000)
+
+	restored := HyperLogLogFromBytes(original.Bytes())
+	if restored.Estimate() != original.Estimate() {
+		t.Fatalf("restored sketch estimates %d, original %d", restored.Estimate(), original.Estimate())
+	}
+
+	// Both keep counting the same way, and the restored one does not share the stored bytes
+	for i := uint64(20000); i < 30000; i++ {
+		original.Add(testHash(i))
+		restored.Add(testHash(i))
+	}
+	if !bytes.Equal(restored.Bytes(), original.Bytes()) {
+		t.Fatal("restored sketch diverged after further adds")
+	}
+
+	for _, data := range [][]byte{nil, make([]byte, 10), make([]byte, hllRegisters+1)} {
+		if estimate := HyperLogLogFromBytes(data).Estimate(); estimate != 0 {
+			t.Errorf("%d bytes restored to a sketch estimating %d, want an empty one", len(data), estimate)
+		}
+	}
+}
+
+func TestHyperLogLogMerge(t *testing.T) {
+	// Overlapping sets, e.g. the same visitors on two days
+	merged := sketchOf(0, 60000)
+	merged.Merge(sketchOf(40000, 100000))
+
+	if union := sketchOf(0, 100000); !bytes.Equal(merged.Bytes(), union.Bytes()) {
+		t.Fatal("merged sketch differs from a sketch of the union")
+	}
+	if err := relativeError(merged.Estimate(), 100000); err > 0.05 {
+		t.Errorf("merged sketch estimates %d, want about 100000", merged.Estimate())
+	}
+
+	// Merging survives storage
+	restored := HyperLogLogFromBytes(merged.Bytes())
+	restored.Merge(sketchOf(100000, 150000))
+	if err := relativeError(restored.Estimate(), 150000); err > 0.05 {
+		t.Errorf("restored and merged sketch estimates %d, want about 150000", restored.Estimate())
+	}
+}
@@ -0,0 +1,230 @@
+package analytics
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dayLayout formats the UTC day visitor data is grouped by.
+const dayLayout = "2006-01-02"
+
+var (
+	saltMu    sync.Mutex
+	saltCache = map[string]string{}
+)
+
+// RecordVisit counts a visit to a link towards its unique clicks. A visitor is identified by a
+// hash of the day's salt, their IP address and user agent, so the same visitor is counted at
+// most once per day and no IP address is stored.
+func RecordVisit(linkID uint, ip string, userAgent string, now time.Time) error {
+	day := now.UTC().Format(dayLayout)
+
+	salt, err := saltForDay(day)
+	if err != nil {
+		return err
+	}
+	visitorHash := hashVisitor(salt, ip, userAgent)
+
+	return initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the link's day so concurrent visits cannot be counted twice
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LinkVisitorDay{LinkID: linkID, Day: day}).Error
+		if err != nil {
+			return err
+		}
+
+		var visitorDay models.LinkVisitorDay
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("link_id = ? AND day = ?", linkID, day).
+			First(&visitorDay).Error
+		if err != nil {
+			return err
+		}
+
+		visitors := dbVisitorSet{tx: tx, linkID: linkID, day: day}
+		counted, sketch, changed, err := countVisitor(visitors, visitorDay.Counted, visitorDay.Sketch, visitorHash, initializers.UniqueVisitorExactLimit())
+		if err != nil || !changed {
+			return err
+		}
+
+		err = tx.Model(&models.LinkVisitorDay{}).
+			Where("link_id = ? AND day = ?", linkID, day).
+			Updates(map[string]interface{}{"counted": counted, "sketch": sketch}).Error
+		if err != nil {
+			return err
+		}
+
+		if added := counted - visitorDay.Counted; added > 0 {
+			return tx.Model(&models.Link{}).Where("id = ?", linkID).
+				UpdateColumn("unique_clicks", gorm.Expr("unique_clicks + ?", added)).Error
+		}
+		return nil
+	})
+}
+
+// PruneVisitorData deletes the salts, visitor hashes and sketches of days before now.
+// Once a day's salt is gone its hashes can no longer be tied to anyone.
+func PruneVisitorData(now time.Time) error {
+	today := now.UTC().Format(dayLayout)
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("day < ?", today).Delete(&models.LinkVisitor{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("day < ?", today).Delete(&models.LinkVisitorDay{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("day < ?", today).Delete(&models.VisitorSalt{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	saltMu.Lock()
+	for day := range saltCache {
+		if day < today {
+			delete(saltCache, day)
+		}
+	}
+	saltMu.Unlock()
+
+	return nil
+}
+
+// Helper function: Load the day's salt, creating it if this is the first visit of the day.
+// Every instance shares the salt through the database so hashes agree.
+func saltForDay(day string) (string, error) {
+	saltMu.Lock()
+	defer saltMu.Unlock()
+
+	if salt, ok := saltCache[day]; ok {
+		return salt, nil
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	err := initializers.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.VisitorSalt{Day: day, Salt: hex.EncodeToString(random)}).Error
+	if err != nil {
+		return "", err
+	}
+
+	// Another instance may have created the salt first
+	var salt models.VisitorSalt
+	if err := initializers.DB.Where("day = ?", day).First(&salt).Error; err != nil {
+		return "", err
+	}
+
+	saltCache[day] = salt.Salt
+	return salt.Salt, nil
+}
+
+// visitorSet holds the exact visitor hashes of one link and day.
+type visitorSet interface {
+	// Add stores a hash and reports whether it was new.
+	Add(visitorHash string) (bool, error)
+	Hashes() ([]string, error)
+	Clear() error
+}
+
+// Helper function: Count a visitor towards a link's day, which holds counted visitors so far and,
+// once it grew past exactLimit, a sketch instead of exact hashes. Reports whether anything changed.
+func countVisitor(visitors visitorSet, counted int, sketch []byte, visitorHash string, exactLimit int) (int, []byte, bool, error) {
+	if sketch == nil {
+		added, err := visitors.Add(visitorHash)
+		if err != nil || !added {
+			// Already counted today
+			return counted, sketch, false, err
+		}
+		counted++
+
+		// Busy links switch to a fixed-size sketch instead of one row per visitor
+		if counted >= exactLimit {
+			if sketch, err = convertToSketch(visitors); err != nil {
+				return counted, nil, false, err
+			}
+		}
+		return counted, sketch, true, nil
+	}
+
+	hll := HyperLogLogFromBytes(sketch)
+	if !hll.Add(hashValue(visitorHash)) {
+		return counted, sketch, false, nil
+	}
+
+	// The count never goes down, even when the estimate starts below the exact count
+	if estimate := hll.Estimate(); estimate > counted {
+		counted = estimate
+	}
+	return counted, hll.Bytes(), true, nil
+}
+
+// Helper function: Move a day's exact visitor hashes into a sketch and delete them
+func convertToSketch(visitors visitorSet) ([]byte, error) {
+	hashes, err := visitors.Hashes()
+	if err != nil {
+		return nil, err
+	}
+
+	hll := NewHyperLogLog()
+	for _, visitorHash := range hashes {
+		hll.Add(hashValue(visitorHash))
+	}
+
+	if err := visitors.Clear(); err != nil {
+		return nil, err
+	}
+	return hll.Bytes(), nil
+}
+
+// dbVisitorSet keeps exact visitor hashes as link_visitors rows.
+type dbVisitorSet struct {
+	tx     *gorm.DB
+	linkID uint
+	day    string
+}
+
+func (s dbVisitorSet) Add(visitorHash string) (bool, error) {
+	result := s.tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.LinkVisitor{LinkID: s.linkID, Day: s.day, VisitorHash: visitorHash})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (s dbVisitorSet) Hashes() ([]string, error) {
+	var hashes []string
+	err := s.tx.Model(&models.LinkVisitor{}).
+		Where("link_id = ? AND day = ?", s.linkID, s.day).
+		Pluck("visitor_hash", &hashes).Error
+	return hashes, err
+}
+
+func (s dbVisitorSet) Clear() error {
+	return s.tx.Where("link_id = ? AND day = ?", s.linkID, s.day).Delete(&models.LinkVisitor{}).Error
+}
+
+func hashVisitor(salt string, ip string, userAgent string) string {
+	sum := sha256.Sum256([]byte(salt + "|" + ip + "|" + userAgent))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Helper function: The first 64 bits of a visitor hash, used as the sketch input
+func hashValue(visitorHash string) uint64 {
+	raw, err := hex.DecodeString(visitorHash)
+	if err != nil || len(raw) < 8 {
+		sum := sha256.Sum256([]byte(visitorHash))
+		raw = sum[:]
+	}
+	return binary.BigEndian.Uint64(raw[:8])
+}
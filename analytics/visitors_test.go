@@ -0,0 +1,118 @@
+package analytics
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+)
+
+// memoryVisitorSet stands in for the link_visitors rows of one link and day.
+type memoryVisitorSet map[string]bool
+
+func (s memoryVisitorSet) Add(visitorHash string) (bool, error) {
+	if s[visitorHash] {
+		return false, nil
+	}
+	s[visitorHash] = true
+	return true, nil
+}
+
+func (s memoryVisitorSet) Hashes() ([]string, error) {
+	hashes := []string{}
+	for visitorHash := range s {
+		hashes = append(hashes, visitorHash)
+	}
+	return hashes, nil
+}
+
+func (s memoryVisitorSet) Clear() error {
+	for visitorHash := range s {
+		delete(s, visitorHash)
+	}
+	return nil
+}
+
+// visitorDay replays RecordVisit's bookkeeping for one link and day.
+type visitorDay struct {
+	visitors memoryVisitorSet
+	counted  int
+	sketch   []byte
+}
+
+func (d *visitorDay) visit(t *testing.T, visitor int, exactLimit int) bool {
+	t.Helper()
+	visitorHash := hashVisitor("salt", fmt.Sprintf("198.51.100.%d", visitor%256), "agent "+strconv.Itoa(visitor/256))
+
+	counted, sketch, changed, err := countVisitor(d.visitors, d.counted, d.sketch, visitorHash, exactLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counted < d.counted {
+		t.Fatalf("visitor %d lowered the count from %d to %d", visitor, d.counted, counted)
+	}
+	d.counted, d.sketch = counted, sketch
+	return changed
+}
+
+func TestCountVisitorExact(t *testing.T) {
+	day := visitorDay{visitors: memoryVisitorSet{}}
+
+	for visitor := 0; visitor < 50; visitor++ {
+		if !day.visit(t, visitor, 100) {
+			t.Fatalf("new visitor %d was not counted", visitor)
+		}
+	}
+	// Returning visitors are not counted again
+	for visitor := 0; visitor < 50; visitor++ {
+		if day.visit(t, visitor, 100) {
+			t.Fatalf("returning visitor %d was counted", visitor)
+		}
+	}
+
+	if day.counted != 50 || len(day.visitors) != 50 || day.sketch != nil {
+		t.Fatalf("counted %d with %d hashes and sketch %v, want 50 exact", day.counted, len(day.visitors), day.sketch != nil)
+	}
+}
+
+func TestCountVisitorSwitchesToSketchAtExactLimit(t *testing.T) {
+	t.Setenv("UNIQUE_VISITOR_EXACT_LIMIT", "100")
+	limit := initializers.UniqueVisitorExactLimit()
+	day := visitorDay{visitors: memoryVisitorSet{}}
+
+	for visitor := 0; visitor < limit-1; visitor++ {
+		day.visit(t, visitor, limit)
+	}
+	if day.sketch != nil {
+		t.Fatalf("switched to a sketch at %d visitors, before the limit of %d", day.counted, limit)
+	}
+
+	day.visit(t, limit-1, limit)
+	if day.sketch == nil {
+		t.Fatalf("still exact at %d visitors", day.counted)
+	}
+	if day.counted != limit {
+		t.Fatalf("counted %d when switching, want %d", day.counted, limit)
+	}
+	if len(day.visitors) != 0 {
+		t.Fatalf("%d exact hashes kept after switching", len(day.visitors))
+	}
+
+	// Visitors from before the switch are in the sketch and not counted again
+	for visitor := 0; visitor < limit; visitor++ {
+		if day.visit(t, visitor, limit) {
+			t.Fatalf("visitor %d counted again after the switch", visitor)
+		}
+	}
+
+	for visitor := limit; visitor < 10000; visitor++ {
+		day.visit(t, visitor, limit)
+	}
+	if len(day.visitors) != 0 {
+		t.Fatal("exact hashes stored after the switch")
+	}
+	if err := relativeError(day.counted, 10000); err > 0.05 {
+		t.Fatalf("counted %d of 10000 visitors", day.counted)
+	}
+}
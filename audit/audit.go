@@ -0,0 +1,38 @@
+// Package audit records security-relevant events for admins to review.
+package audit
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+// Event types
+const (
+	EventLoginFailed   = "login.failed"
+	EventLoginLocked   = "login.locked"
+	EventLoginUnlocked = "login.unlocked"
+)
+
+// Record stores an event and writes it to the log. Errors are only logged, so auditing never
+// fails the request that caused the event.
+func Record(event models.AuditEvent) {
+	userID := "-"
+	if event.UserID != nil {
+		userID = strconv.FormatUint(uint64(*event.UserID), 10)
+	}
+	log.Printf("audit: %s user=%s email=%q ip=%s %s", event.Type, userID, event.Email, event.IP, event.Detail)
+
+	if err := initializers.DB.Create(&event).Error; err != nil {
+		log.Println("Failed to record audit event:", err)
+	}
+}
+
+// Prune deletes events created before the given time.
+func Prune(before time.Time) (int64, error) {
+	result := initializers.DB.Where("created_at < ?", before).Delete(&models.AuditEvent{})
+	return result.RowsAffected, result.Error
+}
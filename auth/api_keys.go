@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// APIKeyPrefix starts every API key so leaked keys are easy to recognise and scan for.
+const APIKeyPrefix = "shurl_"
+
+// apiKeyVisibleLength is how much of the key is stored in clear to help users tell keys apart.
+const apiKeyVisibleLength = len(APIKeyPrefix) + 8
+
+// GenerateAPIKey returns a new random API key and the visible prefix stored alongside its hash.
+func GenerateAPIKey() (string, string, error) {
+	buf := make([]byte, 30)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, key[:apiKeyVisibleLength], nil
+}
+
+// HashAPIKey returns the SHA-256 hash API keys are looked up by. Keys are long and random,
+// so a fast hash is enough; the key itself is never stored.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
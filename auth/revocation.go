@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"gorm.io/gorm/clause"
+)
+
+// notRevokedTTL is how long a "not revoked" answer is cached. Tokens revoked on this
+// instance take effect immediately; tokens revoked on another instance within this delay.
+const notRevokedTTL = 30 * time.Second
+
+// maxCachedChecks bounds the "not revoked" cache before stale entries are swept.
+const maxCachedChecks = 10000
+
+var revocations = struct {
+	sync.Mutex
+	// jti -> token expiry
+	revoked map[string]time.Time
+	// jti -> when it was last confirmed not revoked
+	checked map[string]time.Time
+}{
+	revoked: map[string]time.Time{},
+	checked: map[string]time.Time{},
+}
+
+// RevokeToken records that the access token with the given jti must no longer be accepted.
+func RevokeToken(jti string, userID uint, expiresAt time.Time) error {
+	err := initializers.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	revocations.Lock()
+	revocations.revoked[jti] = expiresAt
+	delete(revocations.checked, jti)
+	revocations.Unlock()
+
+	return nil
+}
+
+// IsTokenRevoked reports whether the access token with the given jti was revoked.
+// Answers are cached in memory so most requests do not hit the database.
+func IsTokenRevoked(jti string) (bool, error) {
+	now := time.Now()
+
+	revocations.Lock()
+	if _, ok := revocations.revoked[jti]; ok {
+		revocations.Unlock()
+		return true, nil
+	}
+	if checkedAt, ok := revocations.checked[jti]; ok && now.Sub(checkedAt) < notRevokedTTL {
+		revocations.Unlock()
+		return false, nil
+	}
+	revocations.Unlock()
+
+	var revoked models.RevokedToken
+	result := initializers.DB.Where("jti = ?", jti).Limit(1).Find(&revoked)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	revocations.Lock()
+	defer revocations.Unlock()
+
+	if result.RowsAffected > 0 {
+		revocations.revoked[jti] = revoked.ExpiresAt
+		return true, nil
+	}
+
+	if len(revocations.checked) >= maxCachedChecks {
+		for cachedJTI, checkedAt := range revocations.checked {
+			if now.Sub(checkedAt) >= notRevokedTTL {
+				delete(revocations.checked, cachedJTI)
+			}
+		}
+	}
+	revocations.checked[jti] = now
+
+	return false, nil
+}
+
+// PruneRevokedTokens forgets revocations of tokens that have expired anyway.
+func PruneRevokedTokens(now time.Time) error {
+	if err := initializers.DB.Where("expires_at < ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+		return err
+	}
+
+	revocations.Lock()
+	defer revocations.Unlock()
+
+	for jti, expiresAt := range revocations.revoked {
+		if expiresAt.Before(now) {
+			delete(revocations.revoked, jti)
+		}
+	}
+	for jti, checkedAt := range revocations.checked {
+		if now.Sub(checkedAt) >= notRevokedTTL {
+			delete(revocations.checked, jti)
+		}
+	}
+
+	return nil
+}
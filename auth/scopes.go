@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+// Permission scopes that can be granted to access tokens and API keys
+const (
+	ScopeLinksRead     = "links:read"
+	ScopeLinksWrite    = "links:write"
+	ScopeAnalyticsRead = "analytics:read"
+	ScopeDomains       = "domains:manage"
+	ScopeWebhooks      = "webhooks:manage"
+	ScopeAccount       = "account:manage"
+	ScopeAdmin         = "admin"
+)
+
+// Scopes lists every supported scope.
+var Scopes = []string{
+	ScopeLinksRead,
+	ScopeLinksWrite,
+	ScopeAnalyticsRead,
+	ScopeDomains,
+	ScopeWebhooks,
+	ScopeAccount,
+	ScopeAdmin,
+}
+
+// IsValidScope reports whether scope is a supported scope.
+func IsValidScope(scope string) bool {
+	for _, known := range Scopes {
+		if known == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultScopes are the scopes of a full login: everything except admin, which only
+// admins get.
+func DefaultScopes(role string) []string {
+	scopes := []string{}
+	for _, scope := range Scopes {
+		if scope != ScopeAdmin || role == models.RoleAdmin {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// HasScope reports whether the granted scopes allow required.
+// links:write implies links:read.
+func HasScope(granted []string, required string) bool {
+	for _, scope := range granted {
+		if scope == required || (scope == ScopeLinksWrite && required == ScopeLinksRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// JoinScopes stores scopes as a comma separated list.
+func JoinScopes(scopes []string) string {
+	return strings.Join(scopes, ",")
+}
+
+// SplitScopes parses a comma separated list of scopes.
+func SplitScopes(value string) []string {
+	scopes := []string{}
+	for _, scope := range strings.Split(value, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned by VerifySignedToken
+var (
+	ErrInvalidSignedToken = errors.New("invalid token")
+	ErrExpiredSignedToken = errors.New("token expired")
+)
+
+// Purposes of signed tokens. A token signed for one purpose is rejected for any other.
+const (
+	PurposeEmailVerification  = "email-verification"
+	PurposeTwoFactorChallenge = "two-factor-challenge"
+)
+
+// SignToken returns a URL-safe token carrying subject until expiresAt, signed with SECRET_KEY.
+// Nothing is stored: the token stays valid until it expires unless the subject it carries
+// stops matching, so subjects should include whatever must invalidate it (e.g. the email address).
+func SignToken(purpose string, subject string, expiresAt time.Time) string {
+	payload := subject + "|" + strconv.FormatInt(expiresAt.Unix(), 10)
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signPayload(purpose, payload))
+}
+
+// VerifySignedToken checks a token made by SignToken for the same purpose and returns its subject.
+func VerifySignedToken(purpose string, token string, now time.Time) (string, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return "", ErrInvalidSignedToken
+	}
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidSignedToken
+	}
+	signatureBytes, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return "", ErrInvalidSignedToken
+	}
+
+	payload := string(payloadBytes)
+	if !hmac.Equal(signatureBytes, signPayload(purpose, payload)) {
+		return "", ErrInvalidSignedToken
+	}
+
+	separator := strings.LastIndex(payload, "|")
+	if separator < 0 {
+		return "", ErrInvalidSignedToken
+	}
+	expiresAt, err := strconv.ParseInt(payload[separator+1:], 10, 64)
+	if err != nil {
+		return "", ErrInvalidSignedToken
+	}
+	if now.Unix() > expiresAt {
+		return "", ErrExpiredSignedToken
+	}
+
+	return payload[:separator], nil
+}
+
+func signPayload(purpose string, payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("SECRET_KEY")))
+	mac.Write([]byte(purpose + "\x00" + payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 key of RFC 6238 Appendix B, "12345678901234567890", base32 encoded.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// rfc6238Vectors are the SHA1 test vectors of RFC 6238 Appendix B. The RFC lists 8 digit
+// codes; these are their last 6 digits, which is what 6 digit TOTP produces.
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	for _, vector := range rfc6238Vectors {
+		code, err := TOTPCode(rfc6238Secret, time.Unix(vector.unix, 0))
+		if err != nil {
+			t.Fatalf("TOTPCode at %d: %v", vector.unix, err)
+		}
+		if code != vector.code {
+			t.Errorf("TOTPCode at %d = %s, want %s", vector.unix, code, vector.code)
+		}
+	}
+}
+
+func TestVerifyTOTPRFC6238Vectors(t *testing.T) {
+	for _, vector := range rfc6238Vectors {
+		now := time.Unix(vector.unix, 0)
+		step, ok := VerifyTOTP(rfc6238Secret, vector.code, now, 0)
+		if !ok {
+			t.Errorf("VerifyTOTP rejected %s at %d", vector.code, vector.unix)
+			continue
+		}
+		if step != TOTPStep(now) {
+			t.Errorf("VerifyTOTP at %d returned step %d, want %d", vector.unix, step, TOTPStep(now))
+		}
+	}
+}
+
+func TestVerifyTOTPSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := TOTPStep(now)
+
+	tests := []struct {
+		name   string
+		offset int64
+		want   bool
+	}{
+		{"two periods early", -2, false},
+		{"one period early", -1, true},
+		{"current period", 0, true},
+		{"one period late", 1, true},
+		{"two periods late", 2, false},
+	}
+
+	for _, tt := range tests {
+		code, err := totpCodeAt(rfc6238Secret, current+tt.offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		step, ok := VerifyTOTP(rfc6238Secret, code, now, 0)
+		if ok != tt.want {
+			t.Errorf("%s: VerifyTOTP = %v, want %v", tt.name, ok, tt.want)
+		}
+		if ok && step != current+tt.offset {
+			t.Errorf("%s: matched step %d, want %d", tt.name, step, current+tt.offset)
+		}
+	}
+}
+
+func TestVerifyTOTPRejectsReplay(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, _ := TOTPCode(rfc6238Secret, now)
+
+	step, ok := VerifyTOTP(rfc6238Secret, code, now, 0)
+	if !ok {
+		t.Fatal("first use of the code was rejected")
+	}
+
+	// Storing the matched step as lastStep must reject the same code, even a few seconds later
+	if _, ok := VerifyTOTP(rfc6238Secret, code, now, step); ok {
+		t.Error("code was accepted twice")
+	}
+	if _, ok := VerifyTOTP(rfc6238Secret, code, now.Add(5*time.Second), step); ok {
+		t.Error("code was accepted twice within its period")
+	}
+
+	// An earlier step's code is rejected once a later step was used
+	previous, _ := totpCodeAt(rfc6238Secret, step-1)
+	if _, ok := VerifyTOTP(rfc6238Secret, previous, now, step); ok {
+		t.Error("code from an earlier step was accepted after a later one")
+	}
+
+	// The next period's code is still fine
+	next, _ := TOTPCode(rfc6238Secret, now.Add(TOTPPeriod))
+	if _, ok := VerifyTOTP(rfc6238Secret, next, now.Add(TOTPPeriod), step); !ok {
+		t.Error("code from the next period was rejected")
+	}
+}
+
+func TestVerifyTOTPRejectsMalformedCodes(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "abcdef", "000000"} {
+		if _, ok := VerifyTOTP(rfc6238Secret, code, now, 0); ok {
+			t.Errorf("VerifyTOTP accepted %q", code)
+		}
+	}
+
+	// Spaces some apps insert between digit groups are ignored
+	if _, ok := VerifyTOTP(rfc6238Secret, " 287 082 ", now, 0); !ok {
+		t.Error("VerifyTOTP rejected a code with spaces")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 || strings.ContainsAny(secret, "=") {
+		t.Errorf("secret %q is not 160 bits of unpadded base32", secret)
+	}
+	if _, err := TOTPCode(secret, time.Now()); err != nil {
+		t.Errorf("generated secret cannot be used: %v", err)
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"k7p2-mxq4-ab3d-9fzw", "k7p2mxq4ab3d9fzw"},
+		{"K7P2-MXQ4-AB3D-9FZW", "k7p2mxq4ab3d9fzw"},
+		{"k7p2 mxq4 ab3d 9fzw", "k7p2mxq4ab3d9fzw"},
+		{"k7p2mxq4ab3d9fzw", "k7p2mxq4ab3d9fzw"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeRecoveryCode(tt.input); got != tt.want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		code, err := GenerateRecoveryCode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parts := strings.Split(code, "-")
+		if len(parts) != 4 {
+			t.Fatalf("code %q is not four groups", code)
+		}
+		for _, part := range parts {
+			if len(part) != 4 || part != strings.ToLower(part) {
+				t.Fatalf("code %q has a malformed group %q", code, part)
+			}
+		}
+
+		// The formatted code must survive normalisation unchanged apart from the dashes
+		if normalized := NormalizeRecoveryCode(strings.ToUpper(code)); normalized != strings.ReplaceAll(code, "-", "") {
+			t.Fatalf("code %q normalises to %q", code, normalized)
+		}
+
+		if seen[code] {
+			t.Fatalf("code %q generated twice", code)
+		}
+		seen[code] = true
+	}
+}
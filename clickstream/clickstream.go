@@ -0,0 +1,121 @@
+package clickstream
+
+import (
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many events a subscriber can fall behind before new events are dropped.
+const subscriberBuffer = 64
+
+// Click is a single redirect, published as it happens.
+type Click struct {
+	LinkID      uint      `json:"-"`
+	UserID      uint      `json:"-"`
+	ShortCode   string    `json:"shortCode"`
+	OriginalURL string    `json:"originalUrl"`
+	Referrer    string    `json:"referrer"`
+	UserAgent   string    `json:"userAgent"`
+	ClickedAt   time.Time `json:"clickedAt"`
+}
+
+// Filter selects which clicks a subscriber receives. A zero field matches everything.
+type Filter struct {
+	UserID uint
+	LinkID uint
+}
+
+func (f Filter) matches(click Click) bool {
+	if f.UserID != 0 && f.UserID != click.UserID {
+		return false
+	}
+	if f.LinkID != 0 && f.LinkID != click.LinkID {
+		return false
+	}
+	return true
+}
+
+// Subscriber receives matching clicks on Events until it is unsubscribed.
+type Subscriber struct {
+	Events <-chan Click
+
+	events  chan Click
+	filter  Filter
+	mu      sync.Mutex
+	dropped int
+}
+
+// TakeDropped returns how many clicks were dropped since the last call because
+// the subscriber was not keeping up, and resets the count.
+func (s *Subscriber) TakeDropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dropped := s.dropped
+	s.dropped = 0
+	return dropped
+}
+
+// Hub fans clicks out to subscribers. Publishing never blocks: a subscriber whose
+// buffer is full misses the click and is told how many it missed instead, so one
+// slow consumer cannot hold up redirects or other consumers.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscriber]struct{}
+}
+
+// NewHub creates an empty hub.
+func NewHub() *Hub {
+	return &Hub{subscribers: map[*Subscriber]struct{}{}}
+}
+
+// Default is the process-wide hub fed by the redirect handler.
+var Default = NewHub()
+
+// Subscribe registers a subscriber for clicks matching the filter.
+func (h *Hub) Subscribe(filter Filter) *Subscriber {
+	events := make(chan Click, subscriberBuffer)
+	sub := &Subscriber{Events: events, events: events, filter: filter}
+
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its Events channel.
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Publish delivers a click to every matching subscriber without blocking.
+func (h *Hub) Publish(click Click) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subscribers {
+		if !sub.filter.matches(click) {
+			continue
+		}
+
+		select {
+		case sub.events <- click:
+		default:
+			sub.mu.Lock()
+			sub.dropped++
+			sub.mu.Unlock()
+		}
+	}
+}
+
+// Publish delivers a click through the default hub.
+func Publish(click Click) {
+	Default.Publish(click)
+}
@@ -0,0 +1,87 @@
+// Command create-admin bootstraps the first admin account.
+//
+// It promotes an existing user to ADMIN, or creates the account when no user has the email:
+//
+//	go run ./cmd/create-admin -email admin@example.com
+//	ADMIN_PASSWORD=... go run ./cmd/create-admin -email admin@example.com -name "Site Admin"
+//
+// Once an admin exists further admins should be promoted through the admin API;
+// pass -force to run it anyway.
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func main() {
+	email := flag.String("email", "", "email of the account to make admin (required)")
+	name := flag.String("name", "Admin", "name for a newly created account")
+	password := flag.String("password", "", "password for a newly created account (default $ADMIN_PASSWORD)")
+	force := flag.Bool("force", false, "run even if an admin already exists")
+	flag.Parse()
+
+	if strings.TrimSpace(*email) == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	initializers.LoadEnvVariables()
+	initializers.ConnectToDB()
+
+	if *password == "" {
+		*password = os.Getenv("ADMIN_PASSWORD")
+	}
+
+	var admins int64
+	if err := initializers.DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins).Error; err != nil {
+		log.Fatal("Failed to count admins: ", err)
+	}
+	if admins > 0 && !*force {
+		log.Fatalf("%d admin(s) already exist; use the admin API or pass -force", admins)
+	}
+
+	var user models.User
+	err := initializers.DB.Where("email = ?", *email).First(&user).Error
+
+	switch {
+	case err == nil:
+		updates := map[string]interface{}{"role": models.RoleAdmin, "disabled_at": nil}
+		if err := initializers.DB.Model(&user).Updates(updates).Error; err != nil {
+			log.Fatal("Failed to promote user: ", err)
+		}
+		log.Printf("Promoted %s (user %d) to %s", user.Email, user.ID, models.RoleAdmin)
+
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if len(*password) < 6 {
+			log.Fatal("No user with that email; pass -password or set ADMIN_PASSWORD (at least 6 characters) to create one")
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(*password), 10)
+		if err != nil {
+			log.Fatal("Failed to hash the password: ", err)
+		}
+
+		user = models.User{
+			Email:    *email,
+			Password: string(hash),
+			Role:     models.RoleAdmin,
+			Name:     *name,
+		}
+		if err := initializers.DB.Create(&user).Error; err != nil {
+			log.Fatal("Failed to create user: ", err)
+		}
+		log.Printf("Created admin %s (user %d)", user.Email, user.ID)
+
+	default:
+		log.Fatal("Failed to look up user: ", err)
+	}
+}
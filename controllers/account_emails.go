@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+
+	"github.com/olujimiAdebakin/Shurl/mailer"
+)
+
+//go:embed templates/verify_email.txt
+var verifyEmailText string
+
+//go:embed templates/verify_email.html
+var verifyEmailHTML string
+
+//go:embed templates/reset_password.txt
+var resetPasswordText string
+
+//go:embed templates/reset_password.html
+var resetPasswordHTML string
+
+// accountEmail is a transactional email about the user's account, rendered from a text and an HTML template.
+type accountEmail struct {
+	subject string
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+var verifyEmail = accountEmail{
+	subject: "Verify your email address",
+	text:    texttemplate.Must(texttemplate.New("verify_email.txt").Parse(verifyEmailText)),
+	html:    htmltemplate.Must(htmltemplate.New("verify_email.html").Parse(verifyEmailHTML)),
+}
+
+var resetPasswordEmail = accountEmail{
+	subject: "Reset your password",
+	text:    texttemplate.Must(texttemplate.New("reset_password.txt").Parse(resetPasswordText)),
+	html:    htmltemplate.Must(htmltemplate.New("reset_password.html").Parse(resetPasswordHTML)),
+}
+
+// Helper function: Render an account email and send it through the default mailer
+func sendAccountEmail(ctx context.Context, email accountEmail, to string, data interface{}) error {
+	var text, html bytes.Buffer
+	if err := email.text.Execute(&text, data); err != nil {
+		return err
+	}
+	if err := email.html.Execute(&html, data); err != nil {
+		return err
+	}
+
+	return mailer.Send(ctx, mailer.Message{
+		To:      to,
+		Subject: email.subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	})
+}
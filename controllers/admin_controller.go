@@ -0,0 +1,864 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/audit"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/loginguard"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/webhooks"
+	"gorm.io/gorm"
+)
+
+// Page sizes for the admin listings
+const (
+	defaultAdminPageSize = 50
+	maxAdminPageSize     = 200
+)
+
+// AdminGetUsers godoc
+// @Summary List users
+// @Description List and search all user accounts (admins only)
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param q query string false "Search name and email"
+// @Param role query string false "Only users with this role (USER or ADMIN)"
+// @Param disabled query bool false "Only disabled (true) or active (false) accounts"
+// @Param page query int false "Page number, from 1"
+// @Param pageSize query int false "Results per page (default 50, max 200)"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.AdminUserListResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /admin/users [get]
+func AdminGetUsers(c *gin.Context) {
+	page, pageSize, ok := adminPageParams(c)
+	if !ok {
+		return
+	}
+
+	query := initializers.DB.Model(&models.User{}).Session(&gorm.Session{})
+
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	if disabled := c.Query("disabled"); disabled != "" {
+		isDisabled, err := strconv.ParseBool(disabled)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "disabled must be true or false",
+			})
+			return
+		}
+		if isDisabled {
+			query = query.Where("disabled_at IS NOT NULL")
+		} else {
+			query = query.Where("disabled_at IS NULL")
+		}
+	}
+
+	var total int64
+	var users []models.User
+	err := query.Count(&total).Error
+	if err == nil {
+		err = query.Order("id").Offset((page - 1) * pageSize).Limit(pageSize).Find(&users).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load users",
+		})
+		return
+	}
+
+	linkCounts := countUserLinks(users)
+	userResponses := []dtos.AdminUserResponse{}
+	for _, user := range users {
+		userResponses = append(userResponses, toAdminUserResponse(user, linkCounts[user.ID]))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.AdminUserListResponse{
+			Users:    userResponses,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	})
+}
+
+// AdminGetUser godoc
+// @Summary Get a user
+// @Description Retrieve any user account (admins only), including failed logins and any active login lockout
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.AdminUserResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Router /admin/users/{id} [get]
+func AdminGetUser(c *gin.Context) {
+	user, ok := findAdminUser(c)
+	if !ok {
+		return
+	}
+
+	userResponse := toAdminUserResponse(user, countUserLinks([]models.User{user})[user.ID])
+
+	// Counters are keyed by email, so they exist for unregistered addresses too and live outside the users table
+	if attempts, err := loginguard.Default().Status(c.Request.Context(), user.Email, time.Now()); err == nil {
+		userResponse.FailedLogins = attempts.Failures
+		userResponse.LoginLockedUntil = attempts.LockedUntil
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    userResponse,
+	})
+}
+
+// AdminDisableUser godoc
+// @Summary Disable a user
+// @Description Disable an account (admins only). The user is logged out everywhere and their API keys stop working
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.AdminUserResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /admin/users/{id}/disable [post]
+func AdminDisableUser(c *gin.Context) {
+	user, ok := findAdminTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if user.DisabledAt == nil {
+		now := time.Now()
+		err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&user).Update("disabled_at", now).Error; err != nil {
+				return err
+			}
+			return invalidateSessions(tx, user.ID, now)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to disable user",
+			})
+			return
+		}
+		user.DisabledAt = &now
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toAdminUserResponse(user, countUserLinks([]models.User{user})[user.ID]),
+	})
+}
+
+// AdminEnableUser godoc
+// @Summary Enable a user
+// @Description Re-enable a disabled account (admins only). The user has to log in again
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.AdminUserResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /admin/users/{id}/enable [post]
+func AdminEnableUser(c *gin.Context) {
+	user, ok := findAdminTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if err := initializers.DB.Model(&user).Update("disabled_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to enable user",
+		})
+		return
+	}
+	user.DisabledAt = nil
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toAdminUserResponse(user, countUserLinks([]models.User{user})[user.ID]),
+	})
+}
+
+// AdminUpdateUserRole godoc
+// @Summary Change a user's role
+// @Description Promote a user to ADMIN or demote them to USER (admins only). Admins cannot change their own role
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param input body dtos.UpdateUserRoleRequest true "New role"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.AdminUserResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /admin/users/{id}/role [put]
+func AdminUpdateUserRole(c *gin.Context) {
+	var req dtos.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	user, ok := findAdminTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if err := initializers.DB.Model(&user).Update("role", req.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to change role",
+		})
+		return
+	}
+	user.Role = req.Role
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toAdminUserResponse(user, countUserLinks([]models.User{user})[user.ID]),
+	})
+}
+
+// AdminGetLinks godoc
+// @Summary List links
+// @Description List and search the links of all users (admins only)
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param q query string false "Search title, URL and short code"
+// @Param userId query int false "Only links owned by this user"
+// @Param includeDeleted query bool false "Include links in the trash"
+// @Param page query int false "Page number, from 1"
+// @Param pageSize query int false "Results per page (default 50, max 200)"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.AdminLinkListResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /admin/links [get]
+func AdminGetLinks(c *gin.Context) {
+	page, pageSize, ok := adminPageParams(c)
+	if !ok {
+		return
+	}
+
+	query := initializers.DB.Model(&models.Link{}).Session(&gorm.Session{})
+	if c.Query("includeDeleted") == "true" {
+		query = query.Unscoped()
+	}
+
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("title ILIKE ? OR original_url ILIKE ? OR short_code ILIKE ?", pattern, pattern, pattern)
+	}
+
+	if userID := c.Query("userId"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Invalid user ID",
+			})
+			return
+		}
+		query = query.Where("user_id = ?", id)
+	}
+
+	var total int64
+	var links []models.Link
+	err := query.Count(&total).Error
+	if err == nil {
+		err = withLinkRelations(query).Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&links).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load links",
+		})
+		return
+	}
+
+	ownerEmails := linkOwnerEmails(links)
+	linkResponses := []dtos.AdminLinkResponse{}
+	for _, link := range links {
+		linkResponses = append(linkResponses, toAdminLinkResponse(c, link, ownerEmails[link.UserID]))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.AdminLinkListResponse{
+			Links:    linkResponses,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	})
+}
+
+// AdminGetLink godoc
+// @Summary Get a link
+// @Description Retrieve any link by ID, including links in the trash (admins only)
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Link ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.AdminLinkResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Router /admin/links/{id} [get]
+func AdminGetLink(c *gin.Context) {
+	link, ok := findAdminLink(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toAdminLinkResponse(c, link, linkOwnerEmails([]models.Link{link})[link.UserID]),
+	})
+}
+
+// AdminDeleteLink godoc
+// @Summary Force-delete a link
+// @Description Permanently delete any link, live or in the trash, e.g. for abuse (admins only). The owner's webhooks receive link.deleted
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Link ID"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /admin/links/{id} [delete]
+func AdminDeleteLink(c *gin.Context) {
+	link, ok := findAdminLink(c)
+	if !ok {
+		return
+	}
+
+	// Build the payload before the relations are gone
+	payload := toLinkResponse(c, link)
+
+	// Hard delete; aliases, revisions and analytics are removed by the ON DELETE CASCADE constraints
+	if err := initializers.DB.Unscoped().Delete(&link).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete link",
+		})
+		return
+	}
+
+	webhooks.Enqueue(link.UserID, webhooks.EventLinkDeleted, payload)
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Link permanently deleted",
+		},
+	})
+}
+
+// AdminGetStats godoc
+// @Summary System-wide statistics
+// @Description Counts of users, links, clicks and integrations across the whole instance (admins only)
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.SystemStatsResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /admin/stats [get]
+func AdminGetStats(c *gin.Context) {
+	db := initializers.DB
+	now := time.Now()
+	weekAgo := now.AddDate(0, 0, -7)
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	var stats dtos.SystemStatsResponse
+	var clickTotals struct {
+		Clicks       int64
+		UniqueClicks int64
+		BotClicks    int64
+	}
+
+	// Stop at the first failing query
+	steps := []func() error{
+		func() error { return db.Model(&models.User{}).Count(&stats.Users).Error },
+		func() error {
+			return db.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&stats.AdminUsers).Error
+		},
+		func() error {
+			return db.Model(&models.User{}).Where("disabled_at IS NOT NULL").Count(&stats.DisabledUsers).Error
+		},
+		func() error {
+			return db.Model(&models.User{}).Where("created_at >= ?", weekAgo).Count(&stats.NewUsers7d).Error
+		},
+		func() error { return db.Model(&models.Link{}).Count(&stats.Links).Error },
+		func() error {
+			return db.Unscoped().Model(&models.Link{}).Where("deleted_at IS NOT NULL").Count(&stats.TrashedLinks).Error
+		},
+		func() error {
+			return db.Model(&models.Link{}).Where("created_at >= ?", weekAgo).Count(&stats.NewLinks7d).Error
+		},
+		func() error {
+			return db.Model(&models.Link{}).Where("health_status = ?", models.HealthBroken).Count(&stats.BrokenLinks).Error
+		},
+		func() error {
+			return db.Model(&models.Link{}).
+				Select("COALESCE(SUM(clicks), 0) AS clicks, COALESCE(SUM(unique_clicks), 0) AS unique_clicks, COALESCE(SUM(bot_clicks), 0) AS bot_clicks").
+				Scan(&clickTotals).Error
+		},
+		func() error {
+			return db.Model(&models.LinkClickDay{}).
+				Select("COALESCE(SUM(clicks), 0)").
+				Where("day >= ?", today.AddDate(0, 0, -6).Format("2006-01-02")).
+				Scan(&stats.Clicks7d).Error
+		},
+		func() error {
+			return db.Model(&models.LinkClickDay{}).
+				Select("COALESCE(SUM(clicks), 0)").
+				Where("day >= ?", today.AddDate(0, 0, -29).Format("2006-01-02")).
+				Scan(&stats.Clicks30d).Error
+		},
+		func() error { return db.Model(&models.Domain{}).Count(&stats.Domains).Error },
+		func() error { return db.Model(&models.Webhook{}).Count(&stats.Webhooks).Error },
+		func() error { return db.Model(&models.APIKey{}).Count(&stats.APIKeys).Error },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to load statistics",
+			})
+			return
+		}
+	}
+
+	stats.Clicks = clickTotals.Clicks
+	stats.UniqueClicks = clickTotals.UniqueClicks
+	stats.BotClicks = clickTotals.BotClicks
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    stats,
+	})
+}
+
+// AdminUnlockUser godoc
+// @Summary Unlock a user's login
+// @Description Lift the login lockouts of an account for every IP and forget its failed logins (admins only)
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.AdminUserResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /admin/users/{id}/unlock [post]
+func AdminUnlockUser(c *gin.Context) {
+	user, ok := findAdminUser(c)
+	if !ok {
+		return
+	}
+
+	if !unlockLogin(c, loginguard.AccountKey(user.Email), &user.ID, user.Email) {
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toAdminUserResponse(user, countUserLinks([]models.User{user})[user.ID]),
+	})
+}
+
+// AdminGetLoginLockouts godoc
+// @Summary List login lockouts
+// @Description List the accounts and IP addresses currently locked out of logging in after repeated failures (admins only)
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.LoginLockoutResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /admin/login-lockouts [get]
+func AdminGetLoginLockouts(c *gin.Context) {
+	locked, err := loginguard.Default().Locked(c.Request.Context(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load login lockouts",
+		})
+		return
+	}
+
+	lockouts := []dtos.LoginLockoutResponse{}
+	for _, attempts := range locked {
+		lockouts = append(lockouts, dtos.LoginLockoutResponse{
+			Key:           attempts.Key,
+			Failures:      attempts.Failures,
+			LastFailureAt: attempts.LastFailureAt,
+			LockedUntil:   attempts.LockedUntil,
+		})
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    lockouts,
+	})
+}
+
+// AdminClearLoginLockout godoc
+// @Summary Clear a login lockout
+// @Description Lift the lockout of an account, an account for one IP address, or an IP address and forget its failed logins.
+// @Description Clearing an account key clears its lockouts for every IP (admins only)
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param key query string true "Lockout key, e.g. account:john@example.com, account:john@example.com|ip:203.0.113.7 or ip:203.0.113.7"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /admin/login-lockouts [delete]
+func AdminClearLoginLockout(c *gin.Context) {
+	key := c.Query("key")
+	if !loginguard.IsValidKey(key) {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "key must start with account: or ip:",
+		})
+		return
+	}
+
+	email, _ := loginguard.ParseKey(key)
+	if !unlockLogin(c, key, nil, email) {
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Login lockout cleared",
+		},
+	})
+}
+
+// AdminGetAuditEvents godoc
+// @Summary List audit events
+// @Description List security events such as failed logins and lockouts, newest first (admins only).
+// @Description Events are kept for AUDIT_RETENTION_DAYS
+// @Tags Admin
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param type query string false "Only events of this type, e.g. login.failed"
+// @Param userId query int false "Only events concerning this user"
+// @Param email query string false "Only events for this email"
+// @Param ip query string false "Only events from this IP address"
+// @Param page query int false "Page number, from 1"
+// @Param pageSize query int false "Results per page (default 50, max 200)"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.AuditEventListResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /admin/audit-events [get]
+func AdminGetAuditEvents(c *gin.Context) {
+	page, pageSize, ok := adminPageParams(c)
+	if !ok {
+		return
+	}
+
+	query := initializers.DB.Model(&models.AuditEvent{}).Session(&gorm.Session{})
+
+	if eventType := c.Query("type"); eventType != "" {
+		query = query.Where("type = ?", eventType)
+	}
+	if userID := c.Query("userId"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "userId must be a number",
+			})
+			return
+		}
+		query = query.Where("user_id = ?", id)
+	}
+	if email := c.Query("email"); email != "" {
+		query = query.Where("LOWER(email) = LOWER(?)", email)
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+
+	var total int64
+	var events []models.AuditEvent
+	err := query.Count(&total).Error
+	if err == nil {
+		err = query.Order("created_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&events).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load audit events",
+		})
+		return
+	}
+
+	eventResponses := []dtos.AuditEventResponse{}
+	for _, event := range events {
+		eventResponses = append(eventResponses, dtos.AuditEventResponse{
+			ID:        event.ID,
+			Type:      event.Type,
+			UserID:    event.UserID,
+			Email:     event.Email,
+			IP:        event.IP,
+			ActorID:   event.ActorID,
+			Detail:    event.Detail,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.AuditEventListResponse{
+			Events:   eventResponses,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	})
+}
+
+// Helper function: Lift a login lockout and audit which admin did it, writing the 500 response on failure
+func unlockLogin(c *gin.Context, key string, userID *uint, email string) bool {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return false
+	}
+
+	if err := loginguard.Default().Unlock(c.Request.Context(), key); err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to clear login lockout",
+		})
+		return false
+	}
+
+	_, ip := loginguard.ParseKey(key)
+	audit.Record(models.AuditEvent{
+		Type:    audit.EventLoginUnlocked,
+		UserID:  userID,
+		Email:   email,
+		IP:      ip,
+		ActorID: &contextUser.ID,
+		Detail:  "unlocked " + key + " by " + contextUser.Email,
+	})
+	return true
+}
+
+// Helper function: Read the page and pageSize query parameters, writing the 400 response on failure
+func adminPageParams(c *gin.Context) (int, int, bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "page must be a positive number",
+		})
+		return 0, 0, false
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", strconv.Itoa(defaultAdminPageSize)))
+	if err != nil || pageSize < 1 || pageSize > maxAdminPageSize {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "pageSize must be between 1 and " + strconv.Itoa(maxAdminPageSize),
+		})
+		return 0, 0, false
+	}
+
+	return page, pageSize, true
+}
+
+// Helper function: Load the user named by the :id parameter, writing the 404 response on failure
+func findAdminUser(c *gin.Context) (models.User, bool) {
+	var user models.User
+	if err := initializers.DB.First(&user, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+		return user, false
+	}
+	return user, true
+}
+
+// Helper function: Load the user an admin is about to modify. Admins cannot disable or demote
+// themselves, so an instance is never left without an admin by accident.
+func findAdminTargetUser(c *gin.Context) (models.User, bool) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return models.User{}, false
+	}
+
+	user, ok := findAdminUser(c)
+	if !ok {
+		return user, false
+	}
+
+	if user.ID == contextUser.ID {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You cannot change your own account here",
+		})
+		return user, false
+	}
+
+	return user, true
+}
+
+// Helper function: Load any link by the :id parameter, including links in the trash
+func findAdminLink(c *gin.Context) (models.Link, bool) {
+	var link models.Link
+	if err := withLinkRelations(initializers.DB.Unscoped()).First(&link, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return link, false
+	}
+	return link, true
+}
+
+// Helper function: Number of live links owned by each of the users
+func countUserLinks(users []models.User) map[uint]int64 {
+	counts := map[uint]int64{}
+	if len(users) == 0 {
+		return counts
+	}
+
+	ids := []uint{}
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	var rows []struct {
+		UserID uint
+		Count  int64
+	}
+	initializers.DB.Model(&models.Link{}).
+		Select("user_id, COUNT(*) AS count").
+		Where("user_id IN ?", ids).
+		Group("user_id").
+		Scan(&rows)
+
+	for _, row := range rows {
+		counts[row.UserID] = row.Count
+	}
+	return counts
+}
+
+// Helper function: Email addresses of the owners of the links
+func linkOwnerEmails(links []models.Link) map[uint]string {
+	emails := map[uint]string{}
+	if len(links) == 0 {
+		return emails
+	}
+
+	ids := []uint{}
+	for _, link := range links {
+		ids = append(ids, link.UserID)
+	}
+
+	var users []models.User
+	initializers.DB.Select("id, email").Where("id IN ?", ids).Find(&users)
+
+	for _, user := range users {
+		emails[user.ID] = user.Email
+	}
+	return emails
+}
+
+func toAdminUserResponse(user models.User, links int64) dtos.AdminUserResponse {
+	return dtos.AdminUserResponse{
+		ID:         user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		Role:       user.Role,
+		DisabledAt: user.DisabledAt,
+		Links:      links,
+		CreatedAt:  user.CreatedAt,
+	}
+}
+
+func toAdminLinkResponse(c *gin.Context, link models.Link, ownerEmail string) dtos.AdminLinkResponse {
+	response := dtos.AdminLinkResponse{
+		ID:           link.ID,
+		LinkResponse: toLinkResponse(c, link),
+		OwnerEmail:   ownerEmail,
+	}
+	if link.DeletedAt.Valid {
+		response.DeletedAt = &link.DeletedAt.Time
+	}
+	return response
+}
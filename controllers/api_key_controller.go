@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/auth"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+// GetAPIKeys godoc
+// @Summary List API keys
+// @Description Retrieve the authenticated user's API keys. The keys themselves are never returned again
+// @Tags API Keys
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.APIKeyResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /api-keys [get]
+func GetAPIKeys(c *gin.Context) {
+	contextUser, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	var keys []models.APIKey
+	if err := initializers.DB.Where("user_id = ?", contextUser.ID).Order("created_at").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load API keys",
+		})
+		return
+	}
+
+	keyResponses := []dtos.APIKeyResponse{}
+	for _, key := range keys {
+		keyResponses = append(keyResponses, toAPIKeyResponse(key))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    keyResponses,
+	})
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create a named API key with the given scopes. The key is only returned in this response.
+// @Description Use it as "Authorization: ApiKey <key>" or "X-API-Key: <key>"
+// @Tags API Keys
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param input body dtos.CreateAPIKeyRequest true "API key details"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.APIKeyResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /api-keys [post]
+func CreateAPIKey(c *gin.Context) {
+	var req dtos.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	contextUser, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	if !validateRequestedScopes(c, req.Scopes, contextUser.Role) {
+		return
+	}
+
+	// A restricted session cannot mint a key with more access than itself
+	contextToken := getContextToken(c)
+	for _, scope := range req.Scopes {
+		if !contextToken.HasScope(scope) {
+			c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Cannot grant a scope your session does not have: " + scope,
+			})
+			return
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "expiresAt must be in the future",
+		})
+		return
+	}
+
+	key, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate API key",
+		})
+		return
+	}
+
+	apiKey := models.APIKey{
+		UserID:    contextUser.ID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   auth.HashAPIKey(key),
+		Scopes:    auth.JoinScopes(req.Scopes),
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	if err := initializers.DB.Create(&apiKey).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create API key",
+		})
+		return
+	}
+
+	keyResponse := toAPIKeyResponse(apiKey)
+	keyResponse.Key = key
+
+	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+		Success: true,
+		Data:    keyResponse,
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke an API key. Requests using it are rejected immediately
+// @Tags API Keys
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /api-keys/{id} [delete]
+func RevokeAPIKey(c *gin.Context) {
+	contextUser, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	var apiKey models.APIKey
+	if err := initializers.DB.Where("id = ? AND user_id = ?", c.Param("id"), contextUser.ID).First(&apiKey).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "API key not found",
+		})
+		return
+	}
+
+	if err := initializers.DB.Delete(&apiKey).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to revoke API key",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "API key revoked successfully",
+		},
+	})
+}
+
+// Helper function: Return the authenticated user, rejecting requests made with an API key.
+// Keys must not be able to mint or revoke other keys.
+func requireSessionUser(c *gin.Context) (ContextUserStruct, bool) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return contextUser, false
+	}
+
+	if getContextToken(c).APIKeyID != 0 {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "API keys cannot manage API keys; log in instead",
+		})
+		return contextUser, false
+	}
+
+	return contextUser, true
+}
+
+func toAPIKeyResponse(apiKey models.APIKey) dtos.APIKeyResponse {
+	return dtos.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     auth.SplitScopes(apiKey.Scopes),
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
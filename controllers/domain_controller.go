@@ -0,0 +1,344 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/utils"
+	"gorm.io/gorm"
+)
+
+// DomainVerifier checks domain ownership. Replace it in tests to avoid real DNS/HTTP lookups.
+var DomainVerifier utils.DomainVerifier = utils.NewDomainVerifier()
+
+// GetDomains godoc
+// @Summary List custom domains
+// @Description Retrieve the authenticated user's custom domains and their verification status
+// @Tags Domains
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.DomainResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /domains [get]
+func GetDomains(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	var domains []models.Domain
+	if err := initializers.DB.Where("user_id = ?", contextUser.ID).Order("hostname").Find(&domains).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load domains",
+		})
+		return
+	}
+
+	domainResponses := []dtos.DomainResponse{}
+	for _, domain := range domains {
+		domainResponses = append(domainResponses, toDomainResponse(domain))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    domainResponses,
+	})
+}
+
+// CreateDomain godoc
+// @Summary Register a custom domain
+// @Description Register a hostname and receive the token needed to verify ownership
+// @Tags Domains
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param input body dtos.DomainRequest true "Domain to register"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.DomainResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 409 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /domains [post]
+func CreateDomain(c *gin.Context) {
+	var req dtos.DomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	token, err := generateVerificationToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate verification token",
+		})
+		return
+	}
+
+	domain := models.Domain{
+		UserID:            contextUser.ID,
+		Hostname:          normalizeHostname(req.Hostname),
+		VerificationToken: token,
+	}
+
+	// Unverified claims by other users do not block registering a hostname, only verified ones
+	var taken int64
+	initializers.DB.Model(&models.Domain{}).
+		Where("hostname = ? AND (verified_at IS NOT NULL OR user_id = ?)", domain.Hostname, contextUser.ID).
+		Count(&taken)
+	if taken > 0 {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Domain is already registered",
+		})
+		return
+	}
+
+	if err := initializers.DB.Create(&domain).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to register domain",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+		Success: true,
+		Data:    toDomainResponse(domain),
+	})
+}
+
+// VerifyDomain godoc
+// @Summary Verify a custom domain
+// @Description Check the DNS TXT record or HTTP well-known file for the domain's verification token
+// @Tags Domains
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Domain ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.DomainResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 409 {object} dtos.ErrorResponse
+// @Failure 422 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /domains/{id}/verify [post]
+func VerifyDomain(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	domain, ok := findOwnedDomain(c, c.Param("id"), contextUser)
+	if !ok {
+		return
+	}
+
+	if domain.VerifiedAt == nil {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		// The reason stays in the log; echoing it would reveal what the server can reach
+		if err := DomainVerifier.Verify(ctx, domain.Hostname, domain.VerificationToken); err != nil {
+			log.Printf("Domain %s could not be verified: %v", domain.Hostname, err)
+			c.JSON(http.StatusUnprocessableEntity, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Domain could not be verified; publish the verification token via DNS TXT record or HTTP well-known file",
+			})
+			return
+		}
+
+		err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&domain).Update("verified_at", time.Now()).Error; err != nil {
+				return err
+			}
+			// Other users' pending claims on the hostname can never be verified now
+			return tx.Unscoped().
+				Where("hostname = ? AND id <> ? AND verified_at IS NULL", domain.Hostname, domain.ID).
+				Delete(&models.Domain{}).Error
+		})
+		if err != nil {
+			// Someone else verified the hostname first; the partial unique index decides
+			if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique") {
+				c.JSON(http.StatusConflict, dtos.ErrorResponse{
+					Success: false,
+					Error:   "Domain was already verified by another account",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to save verification",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toDomainResponse(domain),
+	})
+}
+
+// DeleteDomain godoc
+// @Summary Delete a custom domain
+// @Description Remove a custom domain. Domains that still have links (including deleted links in the trash) cannot be removed
+// @Tags Domains
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Domain ID"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 409 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /domains/{id} [delete]
+func DeleteDomain(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	domain, ok := findOwnedDomain(c, c.Param("id"), contextUser)
+	if !ok {
+		return
+	}
+
+	var linkCount int64
+	initializers.DB.Unscoped().Model(&models.Link{}).Where("domain_id = ?", domain.ID).Count(&linkCount)
+	if linkCount > 0 {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Domain still has links, delete and purge them first",
+		})
+		return
+	}
+
+	if err := initializers.DB.Unscoped().Delete(&domain).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete domain",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Domain deleted successfully",
+		},
+	})
+}
+
+// Helper function: Load a domain by ID scoped to the user, writing the 404 response on failure
+func findOwnedDomain(c *gin.Context, id string, contextUser ContextUserStruct) (models.Domain, bool) {
+	var domain models.Domain
+	result := initializers.DB.Where("id = ? AND user_id = ?", id, contextUser.ID).First(&domain)
+
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Domain not found",
+		})
+		return domain, false
+	}
+
+	return domain, true
+}
+
+// Helper function: Resolve a hostname to a verified custom domain ID.
+// Empty or unknown hostnames resolve to 0, the default domain.
+func domainIDForHost(hostname string) uint {
+	hostname = normalizeHostname(hostname)
+	if hostname == "" {
+		return 0
+	}
+
+	var domain models.Domain
+	err := initializers.DB.Select("id").
+		Where("hostname = ? AND verified_at IS NOT NULL", hostname).
+		First(&domain).Error
+	if err != nil {
+		return 0
+	}
+	return domain.ID
+}
+
+// Helper function: Resolve the optional ?domain= query parameter used by the management endpoints,
+// writing the 404 response when it names a domain that is not registered and verified.
+func queryDomainID(c *gin.Context) (uint, bool) {
+	hostname := c.Query("domain")
+	if hostname == "" {
+		return 0, true
+	}
+
+	domainID := domainIDForHost(hostname)
+	if domainID == 0 {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Domain not found",
+		})
+		return 0, false
+	}
+	return domainID, true
+}
+
+// Helper function: Lowercase a hostname and strip any port
+func normalizeHostname(hostname string) string {
+	hostname = strings.ToLower(strings.TrimSpace(hostname))
+	if host, _, err := net.SplitHostPort(hostname); err == nil {
+		hostname = host
+	}
+	return strings.TrimSuffix(hostname, ".")
+}
+
+// Helper function: Generate a random hex token for domain verification
+func generateVerificationToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func toDomainResponse(domain models.Domain) dtos.DomainResponse {
+	response := dtos.DomainResponse{
+		ID:         domain.ID,
+		Hostname:   domain.Hostname,
+		Verified:   domain.VerifiedAt != nil,
+		VerifiedAt: domain.VerifiedAt,
+	}
+
+	if domain.VerifiedAt == nil {
+		response.Verification = &dtos.DomainVerificationInstructions{
+			TXTRecordName:  utils.DomainTXTPrefix + "." + domain.Hostname,
+			TXTRecordValue: utils.DomainTXTValue(domain.VerificationToken),
+			HTTPURL:        "http://" + domain.Hostname + utils.DomainWellKnownPath,
+			Token:          domain.VerificationToken,
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/auth"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+// verificationResendInterval is the minimum delay between two verification emails to the same user.
+const verificationResendInterval = time.Minute
+
+// VerifyEmail godoc
+// @Summary Verify an email address
+// @Description Confirm the email address using the link sent at signup. Links expire after EMAIL_VERIFICATION_TTL_HOURS
+// @Description and stop working if the address is changed
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param token query string true "Verification token from the email"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/verify-email [get]
+func VerifyEmail(c *gin.Context) {
+	subject, err := auth.VerifySignedToken(auth.PurposeEmailVerification, c.Query("token"), time.Now())
+	if err != nil {
+		message := "Invalid verification link"
+		if errors.Is(err, auth.ErrExpiredSignedToken) {
+			message = "Verification link expired; request a new one"
+		}
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+		return
+	}
+
+	// The subject is "<user ID>:<email>", so links for an old address no longer match
+	userID, email, _ := strings.Cut(subject, ":")
+	id, _ := strconv.ParseUint(userID, 10, 64)
+
+	var user models.User
+	if err := initializers.DB.First(&user, id).Error; err != nil || user.Email != email {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid verification link",
+		})
+		return
+	}
+
+	if user.EmailVerifiedAt == nil {
+		if err := initializers.DB.Model(&user).Update("email_verified_at", time.Now()).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to verify email",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Email verified successfully",
+		},
+	})
+}
+
+// ResendVerificationEmail godoc
+// @Summary Resend the verification email
+// @Description Send a new email verification link to the authenticated user. Limited to one email a minute
+// @Tags Authentication
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 429 {object} dtos.ErrorResponse
+// @Failure 502 {object} dtos.ErrorResponse
+// @Router /users/verify-email/resend [post]
+func ResendVerificationEmail(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := initializers.DB.First(&user, contextUser.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+		return
+	}
+
+	if user.EmailVerifiedAt != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Email is already verified",
+		})
+		return
+	}
+
+	if user.EmailVerificationSentAt != nil && time.Since(*user.EmailVerificationSentAt) < verificationResendInterval {
+		c.JSON(http.StatusTooManyRequests, dtos.ErrorResponse{
+			Success: false,
+			Error:   "A verification email was just sent; try again in a minute",
+		})
+		return
+	}
+
+	if err := sendVerificationEmail(c, user); err != nil {
+		c.JSON(http.StatusBadGateway, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to send verification email: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Verification email sent to " + user.Email,
+		},
+	})
+}
+
+// Helper function: Email the user a signed verification link and record when it was sent
+func sendVerificationEmail(c *gin.Context, user models.User) error {
+	ttl := initializers.EmailVerificationTTL()
+	token := auth.SignToken(auth.PurposeEmailVerification, fmt.Sprintf("%d:%s", user.ID, user.Email), time.Now().Add(ttl))
+
+	data := struct {
+		Name      string
+		Email     string
+		Link      string
+		ExpiresIn string
+	}{
+		Name:      user.Name,
+		Email:     user.Email,
+		Link:      requestBaseURL(c) + "/api/v1/users/verify-email?token=" + url.QueryEscape(token),
+		ExpiresIn: fmt.Sprintf("%d hours", int(ttl.Hours())),
+	}
+
+	if err := sendAccountEmail(c.Request.Context(), verifyEmail, user.Email, data); err != nil {
+		return err
+	}
+
+	return initializers.DB.Model(&user).Update("email_verification_sent_at", time.Now()).Error
+}
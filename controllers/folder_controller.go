@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"gorm.io/gorm"
+)
+
+// GetFolders godoc
+// @Summary List folders
+// @Description Retrieve all of the authenticated user's folders. Use parentId to rebuild the hierarchy
+// @Tags Folders
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.FolderResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /folders [get]
+func GetFolders(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	var folders []models.Folder
+	if err := initializers.DB.Where("user_id = ?", contextUser.ID).Order("name").Find(&folders).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load folders",
+		})
+		return
+	}
+
+	folderResponses := []dtos.FolderResponse{}
+	for _, folder := range folders {
+		folderResponses = append(folderResponses, toFolderResponse(folder))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    folderResponses,
+	})
+}
+
+// CreateFolder godoc
+// @Summary Create a folder
+// @Description Create a folder, optionally nested inside another folder
+// @Tags Folders
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param input body dtos.FolderRequest true "Create folder request"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.FolderResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /folders [post]
+func CreateFolder(c *gin.Context) {
+	var req dtos.FolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	if req.ParentID != nil && !userOwnsFolder(initializers.DB, contextUser.ID, *req.ParentID) {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Parent folder not found",
+		})
+		return
+	}
+
+	folder := models.Folder{
+		UserID:   contextUser.ID,
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	}
+
+	if err := initializers.DB.Create(&folder).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create folder",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+		Success: true,
+		Data:    toFolderResponse(folder),
+	})
+}
+
+// UpdateFolder godoc
+// @Summary Update a folder
+// @Description Rename a folder or move it under another folder
+// @Tags Folders
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Folder ID"
+// @Param input body dtos.FolderUpdateRequest true "Update folder request"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.FolderResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /folders/{id} [patch]
+func UpdateFolder(c *gin.Context) {
+	var req dtos.FolderUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	folder, ok := findOwnedFolder(c, c.Param("id"), contextUser)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			updates["parent_id"] = nil
+		} else {
+			if !userOwnsFolder(initializers.DB, contextUser.ID, *req.ParentID) {
+				c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+					Success: false,
+					Error:   "Parent folder not found",
+				})
+				return
+			}
+
+			if folderIsAncestor(folder.ID, *req.ParentID) {
+				c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+					Success: false,
+					Error:   "A folder cannot be moved inside itself",
+				})
+				return
+			}
+
+			updates["parent_id"] = *req.ParentID
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := initializers.DB.Model(&folder).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to update folder",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toFolderResponse(folder),
+	})
+}
+
+// DeleteFolder godoc
+// @Summary Delete a folder
+// @Description Delete a folder. Its links and sub-folders move up to the folder's parent
+// @Tags Folders
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Folder ID"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /folders/{id} [delete]
+func DeleteFolder(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	folder, ok := findOwnedFolder(c, c.Param("id"), contextUser)
+	if !ok {
+		return
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Folder{}).Where("parent_id = ?", folder.ID).Update("parent_id", folder.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Link{}).Where("folder_id = ?", folder.ID).Update("folder_id", folder.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&folder).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete folder",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Folder deleted successfully",
+		},
+	})
+}
+
+// Helper function: Load a folder by ID scoped to the user, writing the 404 response on failure
+func findOwnedFolder(c *gin.Context, id string, contextUser ContextUserStruct) (models.Folder, bool) {
+	var folder models.Folder
+	result := initializers.DB.Where("id = ? AND user_id = ?", id, contextUser.ID).First(&folder)
+
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Folder not found",
+		})
+		return folder, false
+	}
+
+	return folder, true
+}
+
+// Helper function: Check that a folder exists and belongs to the user
+func userOwnsFolder(tx *gorm.DB, userID uint, folderID uint) bool {
+	var count int64
+	tx.Model(&models.Folder{}).Where("id = ? AND user_id = ?", folderID, userID).Count(&count)
+	return count > 0
+}
+
+// Helper function: Report whether ancestorID is folderID itself or one of its ancestors
+func folderIsAncestor(ancestorID uint, folderID uint) bool {
+	current := &folderID
+	for current != nil {
+		if *current == ancestorID {
+			return true
+		}
+
+		var folder models.Folder
+		if err := initializers.DB.Select("parent_id").First(&folder, *current).Error; err != nil {
+			return false
+		}
+		current = folder.ParentID
+	}
+	return false
+}
+
+func toFolderResponse(folder models.Folder) dtos.FolderResponse {
+	return dtos.FolderResponse{
+		ID:       folder.ID,
+		Name:     folder.Name,
+		ParentID: folder.ParentID,
+	}
+}
+
+// Helper function: Parse an optional numeric folder filter from the query string
+func parseFolderFilter(c *gin.Context) (uint64, bool, error) {
+	value := c.Query("folderId")
+	if value == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	return id, true, err
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"gorm.io/gorm"
+)
+
+// getContextUser returns the authenticated user attached by the auth middleware.
+// It writes the error response itself, so callers only need to return when ok is false.
+func getContextUser(c *gin.Context) (ContextUserStruct, bool) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return ContextUserStruct{}, false
+	}
+
+	contextUser, ok := user.(ContextUserStruct)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user data",
+		})
+		return ContextUserStruct{}, false
+	}
+
+	return contextUser, true
+}
+
+// findOwnedLink loads the link for shortCode (on the domain named by ?domain=) and checks it belongs to the user.
+// It writes the 404/403 response itself, so callers only need to return when ok is false.
+func findOwnedLink(c *gin.Context, shortCode string, contextUser ContextUserStruct) (models.Link, bool) {
+	var link models.Link
+
+	domainID, ok := queryDomainID(c)
+	if !ok {
+		return link, false
+	}
+
+	err := findLinkByCode(withLinkRelations(initializers.DB), domainID, shortCode, &link)
+
+	if err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return link, false
+	}
+
+	// Check ownership
+	if link.UserID != contextUser.ID {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You can only manage your own links",
+		})
+		return link, false
+	}
+
+	return link, true
+}
+
+// withLinkRelations preloads the associations included in link responses.
+func withLinkRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Tags").Preload("Aliases").Preload("Domain")
+}
+
+// findLinkByCode looks up a live link on a domain by its primary short code or any of its aliases.
+// Domain ID 0 is the default domain.
+func findLinkByCode(db *gorm.DB, domainID uint, shortCode string, link *models.Link) error {
+	err := db.Session(&gorm.Session{}).
+		Where("domain_id = ? AND short_code = ?", domainID, shortCode).
+		First(link).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	aliasLinkID := db.Session(&gorm.Session{NewDB: true}).
+		Model(&models.LinkAlias{}).
+		Select("link_id").
+		Where("domain_id = ? AND short_code = ?", domainID, shortCode)
+
+	return db.Session(&gorm.Session{}).Where("id = (?)", aliasLinkID).First(link).Error
+}
+
+// reservedShortCodes are fixed path segments routed next to /:shortCode. A link using one
+// of them could not be reached through the API (e.g. GET /links/trash) or the redirect.
+var reservedShortCodes = map[string]bool{
+	"live":    true,
+	"trash":   true,
+	"broken":  true,
+	"health":  true,
+	"swagger": true,
+	"api":     true,
+}
+
+// isReservedShortCode reports whether a requested short code or alias collides with a route.
+func isReservedShortCode(shortCode string) bool {
+	return reservedShortCodes[strings.ToLower(shortCode)]
+}
+
+// shortCodeTaken reports whether a code is already used on a domain as a primary short code
+// (including links in the trash) or as an alias.
+func shortCodeTaken(db *gorm.DB, domainID uint, shortCode string) bool {
+	var count int64
+	db.Unscoped().Model(&models.Link{}).
+		Where("domain_id = ? AND short_code = ?", domainID, shortCode).
+		Count(&count)
+	if count > 0 {
+		return true
+	}
+
+	db.Model(&models.LinkAlias{}).
+		Where("domain_id = ? AND short_code = ?", domainID, shortCode).
+		Count(&count)
+	return count > 0
+}
@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/webhooks"
+	"gorm.io/gorm"
+)
+
+// AddLinkAlias godoc
+// @Summary Add an alias to a link
+// @Description Add another short code that redirects to the same link and shares its analytics (owner only)
+// @Tags Links
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short code of the link"
+// @Param input body dtos.LinkAliasRequest true "Alias to add"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.LinkResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 409 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /links/{shortCode}/aliases [post]
+func AddLinkAlias(c *gin.Context) {
+	var req dtos.LinkAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	link, ok := findOwnedLink(c, c.Param("shortCode"), contextUser)
+	if !ok {
+		return
+	}
+
+	if isReservedShortCode(req.ShortCode) {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Short code is reserved",
+		})
+		return
+	}
+
+	if shortCodeTaken(initializers.DB, link.DomainID, req.ShortCode) {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Short code already exists",
+		})
+		return
+	}
+
+	alias := models.LinkAlias{
+		ShortCode: req.ShortCode,
+		LinkID:    link.ID,
+		DomainID:  link.DomainID,
+	}
+
+	if err := initializers.DB.Create(&alias).Error; err != nil {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Short code already exists",
+		})
+		return
+	}
+
+	link.Aliases = append(link.Aliases, alias)
+
+	webhooks.Enqueue(link.UserID, webhooks.EventLinkUpdated, toLinkResponse(c, link))
+
+	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+		Success: true,
+		Data:    toLinkResponse(c, link),
+	})
+}
+
+// RemoveLinkAlias godoc
+// @Summary Remove an alias from a link
+// @Description Remove an alias, releasing its short code. The primary short code cannot be removed (owner only)
+// @Tags Links
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short code of the link"
+// @Param alias path string true "Alias to remove"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.LinkResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /links/{shortCode}/aliases/{alias} [delete]
+func RemoveLinkAlias(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	link, ok := findOwnedLink(c, c.Param("shortCode"), contextUser)
+	if !ok {
+		return
+	}
+
+	alias, ok := findLinkAlias(c, link, c.Param("alias"))
+	if !ok {
+		return
+	}
+
+	if err := initializers.DB.Unscoped().Delete(&alias).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to remove alias",
+		})
+		return
+	}
+
+	remaining := []models.LinkAlias{}
+	for _, existing := range link.Aliases {
+		if existing.ID != alias.ID {
+			remaining = append(remaining, existing)
+		}
+	}
+	link.Aliases = remaining
+
+	webhooks.Enqueue(link.UserID, webhooks.EventLinkUpdated, toLinkResponse(c, link))
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toLinkResponse(c, link),
+	})
+}
+
+// SetPrimaryLinkAlias godoc
+// @Summary Make an alias the primary short code
+// @Description Swap an alias with the link's primary short code. The old primary code becomes an alias (owner only)
+// @Tags Links
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short code of the link"
+// @Param alias path string true "Alias to promote"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.LinkResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /links/{shortCode}/aliases/{alias}/primary [post]
+func SetPrimaryLinkAlias(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	link, ok := findOwnedLink(c, c.Param("shortCode"), contextUser)
+	if !ok {
+		return
+	}
+
+	alias, ok := findLinkAlias(c, link, c.Param("alias"))
+	if !ok {
+		return
+	}
+
+	previousPrimary := link.ShortCode
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&alias).Update("short_code", previousPrimary).Error; err != nil {
+			return err
+		}
+		return tx.Model(&link).Update("short_code", c.Param("alias")).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to change primary short code",
+		})
+		return
+	}
+
+	if err := initializers.DB.Where("link_id = ?", link.ID).Find(&link.Aliases).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load aliases",
+		})
+		return
+	}
+
+	webhooks.Enqueue(link.UserID, webhooks.EventLinkUpdated, toLinkResponse(c, link))
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toLinkResponse(c, link),
+	})
+}
+
+// Helper function: Find one of the link's aliases, writing the 404 response on failure
+func findLinkAlias(c *gin.Context, link models.Link, shortCode string) (models.LinkAlias, bool) {
+	for _, alias := range link.Aliases {
+		if alias.ShortCode == shortCode {
+			return alias, true
+		}
+	}
+
+	c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+		Success: false,
+		Error:   "Alias not found",
+	})
+	return models.LinkAlias{}, false
+}
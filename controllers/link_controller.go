@@ -1,19 +1,29 @@
 package controllers
 
 import (
+	"context"
 	"crypto/md5"
-	_"log"
 	"fmt"
+	"log"
 	"math/rand"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/analytics"
+	"github.com/olujimiAdebakin/Shurl/clickstream"
 	"github.com/olujimiAdebakin/Shurl/dtos"
 	"github.com/olujimiAdebakin/Shurl/initializers"
 	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/webhooks"
+	"github.com/olujimiAdebakin/Shurl/utils"
+	"gorm.io/gorm"
 )
 
+// maxTitleLength matches the validation limit on user-supplied titles.
+const maxTitleLength = 200
+
 // CreateLink godoc
 // @Summary Create a new shortened URL
 // @Description Create a new shortened URL with optional custom short code
@@ -63,6 +73,51 @@ func CreateLink(c *gin.Context) {
 	shortCode := req.ShortCode
 	if shortCode == "" {
 		shortCode = generateShortCode()
+	} else if isReservedShortCode(shortCode) {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Short code is reserved",
+		})
+		return
+	}
+
+	// Resolve the custom domain, which must belong to the user and be verified
+	var domain *models.Domain
+	if req.Domain != "" {
+		domain = &models.Domain{}
+		err := initializers.DB.
+			Where("hostname = ? AND user_id = ? AND verified_at IS NOT NULL", normalizeHostname(req.Domain), contextUser.ID).
+			First(domain).Error
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Domain not found or not verified",
+			})
+			return
+		}
+	}
+
+	var domainID uint
+	if domain != nil {
+		domainID = domain.ID
+	}
+
+	// Short codes are shared with aliases, which live in their own table
+	if shortCodeTaken(initializers.DB, domainID, shortCode) {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Short code already exists",
+		})
+		return
+	}
+
+	// Make sure the target folder belongs to the user
+	if req.FolderID != nil && !userOwnsFolder(initializers.DB, contextUser.ID, *req.FolderID) {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Folder not found",
+		})
+		return
 	}
 
 	// Generate hash of the original URL
@@ -75,10 +130,24 @@ func CreateLink(c *gin.Context) {
 		Hash:        hash,
 		Clicks:      0,
 		UserID:      contextUser.ID,
+		DomainID:    domainID,
+		FolderID:    req.FolderID,
+		Title:       req.Title,
+		Description: req.Description,
+		Notes:       req.Notes,
 	}
 
-	// Save link to database
-	if err := initializers.DB.Create(&link).Error; err != nil {
+	// Save link and its tags to database
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		tags, err := resolveTags(tx, contextUser.ID, req.Tags)
+		if err != nil {
+			return err
+		}
+		link.Tags = tags
+		return tx.Create(&link).Error
+	})
+	link.Domain = domain
+	if err != nil {
 		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique") {
 			c.JSON(http.StatusConflict, dtos.ErrorResponse{
 				Success: false,
@@ -94,28 +163,33 @@ func CreateLink(c *gin.Context) {
 		return
 	}
 
+	// Fill in the title from the destination page without delaying the response
+	if link.Title == "" {
+		go fillLinkTitle(link.ID, link.OriginalURL)
+	}
+
 	// Return success response
+	webhooks.Enqueue(link.UserID, webhooks.EventLinkCreated, toLinkResponse(c, link))
+
 	c.JSON(http.StatusCreated, dtos.SuccessResponse{
 		Success: true,
-		Data: dtos.LinkResponse{
-			ShortCode:   link.ShortCode,
-			OriginalURL: link.OriginalURL,
-			Clicks:      link.Clicks,
-			Favicon:     link.Favicon,
-			UserID:      link.UserID,
-		},
+		Data: toLinkResponse(c, link),
 	})
 }
 
 // GetLink godoc
 // @Summary Get link information
-// @Description Retrieve link details by short code and increment click count
+// @Description Retrieve full link details by short code (owner only). Read-only: clicks are only counted on redirects
 // @Tags Links
+// @Security Bearer
 // @Accept json
 // @Produce json
 // @Param shortCode path string true "Short code of the link"
+// @Param domain query string false "Custom domain the short code belongs to"
 // @Success 200 {object} dtos.SuccessResponse{data=dtos.LinkResponse}
 // @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
 // @Failure 404 {object} dtos.ErrorResponse
 // @Failure 500 {object} dtos.ErrorResponse
 // @Router /links/{shortCode} [get]
@@ -130,10 +204,42 @@ func GetLink(c *gin.Context) {
 		return
 	}
 
-	var link models.Link
-	result := initializers.DB.Where("short_code = ?", shortCode).First(&link)
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	link, ok := findOwnedLink(c, shortCode, contextUser)
+	if !ok {
+		return
+	}
+
+	// Return the link
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toLinkResponse(c, link),
+	})
+}
+
+// ResolveLink godoc
+// @Summary Resolve a short code
+// @Description Return only the destination of a short link. Public and read-only: no click is counted
+// @Tags Redirect
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short code of the link"
+// @Param domain query string false "Custom domain the short code belongs to"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ResolveLinkResponse}
+// @Failure 404 {object} dtos.ErrorResponse
+// @Router /resolve/{shortCode} [get]
+func ResolveLink(c *gin.Context) {
+	domainID, ok := queryDomainID(c)
+	if !ok {
+		return
+	}
 
-	if result.Error != nil {
+	var link models.Link
+	if err := findLinkByCode(initializers.DB, domainID, c.Param("shortCode"), &link); err != nil {
 		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
 			Success: false,
 			Error:   "Link not found",
@@ -141,25 +247,19 @@ func GetLink(c *gin.Context) {
 		return
 	}
 
-	// Increment click count
-	initializers.DB.Model(&link).Update("clicks", link.Clicks+1)
-
-	// Return the link
 	c.JSON(http.StatusOK, dtos.SuccessResponse{
 		Success: true,
-		Data: dtos.LinkResponse{
-			ShortCode:   link.ShortCode,
+		Data: dtos.ResolveLinkResponse{
 			OriginalURL: link.OriginalURL,
-			Clicks:      link.Clicks + 1,
-			Favicon:     link.Favicon,
-			UserID:      link.UserID,
 		},
 	})
 }
 
 // RedirectLink godoc
 // @Summary Redirect to original URL
-// @Description Redirect to original URL using short code and increment clicks
+// @Description Redirect to original URL using short code and increment clicks.
+// @Description Requests from bots, link unfurlers and monitors are recorded separately and not counted as clicks.
+// @Description The short code is looked up on the custom domain matching the Host header, or the default domain
 // @Tags Redirect
 // @Accept json
 // @Produce json
@@ -171,7 +271,6 @@ func GetLink(c *gin.Context) {
 // @Router /{shortCode} [get]
 func RedirectLink(c *gin.Context) {
 	shortCode := c.Param("shortCode")
-	fmt.Println("Attempting to redirect shortCode:", shortCode)
 
 	if shortCode == "" {
 		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
@@ -182,10 +281,10 @@ func RedirectLink(c *gin.Context) {
 	}
 
 	var link models.Link
-	result := initializers.DB.Where("short_code = ?", shortCode).First(&link)
-	fmt.Println("Link found:", link.OriginalURL)
+	// Short codes are scoped by the domain the request arrived on
+	err := findLinkByCode(initializers.DB, domainIDForHost(c.Request.Host), shortCode, &link)
 
-	if result.Error != nil {
+	if err != nil {
 		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
 			Success: false,
 			Error:   "Link not found",
@@ -205,9 +304,56 @@ func RedirectLink(c *gin.Context) {
 //     return
 // }
 
+	// Unfurlers, crawlers and monitors are still redirected but are not counted as clicks
+	if bot, isBot := analytics.ClassifyBot(c.Request.UserAgent()); isBot {
+		go func() {
+			if err := analytics.RecordBotHit(link.ID, bot, time.Now()); err != nil {
+				log.Printf("Failed to record bot hit on link %d: %v", link.ID, err)
+			}
+		}()
+
+		c.Redirect(http.StatusMovedPermanently, link.OriginalURL)
+		return
+	}
+
 	// Increment click count asynchronously to avoid blocking the redirect
 	go initializers.DB.Model(&link).Update("clicks", link.Clicks+1)
 
+	// The country header is only trusted when a known proxy or CDN set it
+	country := ""
+	if isTrustedProxy(c.RemoteIP()) {
+		country = c.GetHeader(initializers.CountryHeader())
+	}
+
+	// Record analytics in the background; the context must not be used after the handler returns
+	go func(ip string, userAgent string, referrer string) {
+		now := time.Now()
+		if err := analytics.RecordVisit(link.ID, ip, userAgent, now); err != nil {
+			log.Printf("Failed to record visit to link %d: %v", link.ID, err)
+		}
+		if err := analytics.RecordClick(link.ID, referrer, country, now); err != nil {
+			log.Printf("Failed to record click on link %d: %v", link.ID, err)
+		}
+	}(c.ClientIP(), c.Request.UserAgent(), c.Request.Referer())
+
+	clickstream.Publish(clickstream.Click{
+		LinkID:      link.ID,
+		UserID:      link.UserID,
+		ShortCode:   shortCode,
+		OriginalURL: link.OriginalURL,
+		Referrer:    c.Request.Referer(),
+		UserAgent:   c.Request.UserAgent(),
+		ClickedAt:   time.Now().UTC(),
+	})
+
+	go webhooks.Enqueue(link.UserID, webhooks.EventLinkClicked, map[string]interface{}{
+		"shortCode":   shortCode,
+		"originalUrl": link.OriginalURL,
+		"referrer":    c.Request.Referer(),
+		"userAgent":   c.Request.UserAgent(),
+		"clickedAt":   time.Now().UTC(),
+	})
+
 	// Redirect to original URL (301 = permanent redirect)
 	c.Redirect(http.StatusMovedPermanently, link.OriginalURL)
 }
@@ -261,9 +407,12 @@ func UpdateLink(c *gin.Context) {
 
 	// Find the link
 	var link models.Link
-	result := initializers.DB.Where("short_code = ?", shortCode).First(&link)
+	domainID, ok := queryDomainID(c)
+	if !ok {
+		return
+	}
 
-	if result.Error != nil {
+	if err := findLinkByCode(withLinkRelations(initializers.DB), domainID, shortCode, &link); err != nil {
 		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
 			Success: false,
 			Error:   "Link not found",
@@ -285,6 +434,11 @@ func UpdateLink(c *gin.Context) {
 	if req.OriginalURL != "" {
 		updates["original_url"] = req.OriginalURL
 		updates["hash"] = generateHash(req.OriginalURL)
+
+		// The new destination has not been checked yet
+		if req.OriginalURL != link.OriginalURL {
+			addHealthReset(updates)
+		}
 	}
 
 	if req.IsActive != nil {
@@ -292,19 +446,77 @@ func UpdateLink(c *gin.Context) {
 		// For now, this is a placeholder for future implementation
 	}
 
-	if len(updates) > 0 {
-		initializers.DB.Model(&link).Updates(updates)
+	if req.Title != nil {
+		updates["title"] = *req.Title
+	}
+
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+
+	if req.Notes != nil {
+		updates["notes"] = *req.Notes
 	}
 
+	if req.FolderID != nil {
+		if *req.FolderID == 0 {
+			updates["folder_id"] = nil
+		} else if userOwnsFolder(initializers.DB, contextUser.ID, *req.FolderID) {
+			updates["folder_id"] = *req.FolderID
+		} else {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Folder not found",
+			})
+			return
+		}
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Record the previous destination alongside the update so changes are auditable
+		if req.OriginalURL != "" && req.OriginalURL != link.OriginalURL {
+			if err := recordLinkRevision(tx, &link, contextUser.ID); err != nil {
+				return err
+			}
+		}
+
+		if len(updates) > 0 {
+			if err := tx.Model(&link).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		if req.Tags != nil {
+			tags, err := resolveTags(tx, contextUser.ID, *req.Tags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&link).Association("Tags").Replace(tags); err != nil {
+				return err
+			}
+			link.Tags = tags
+		}
+
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update link",
+		})
+		return
+	}
+
+	// A blank title is refilled from the (possibly new) destination page
+	if link.Title == "" {
+		go fillLinkTitle(link.ID, link.OriginalURL)
+	}
+
+	webhooks.Enqueue(link.UserID, webhooks.EventLinkUpdated, toLinkResponse(c, link))
+
 	c.JSON(http.StatusOK, dtos.SuccessResponse{
 		Success: true,
-		Data: dtos.LinkResponse{
-			ShortCode:   link.ShortCode,
-			OriginalURL: link.OriginalURL,
-			Clicks:      link.Clicks,
-			Favicon:     link.Favicon,
-			UserID:      link.UserID,
-		},
+		Data: toLinkResponse(c, link),
 	})
 }
 
@@ -346,9 +558,12 @@ func DeleteLink(c *gin.Context) {
 
 	// Find the link
 	var link models.Link
-	result := initializers.DB.Where("short_code = ?", shortCode).First(&link)
+	domainID, ok := queryDomainID(c)
+	if !ok {
+		return
+	}
 
-	if result.Error != nil {
+	if err := findLinkByCode(withLinkRelations(initializers.DB), domainID, shortCode, &link); err != nil {
 		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
 			Success: false,
 			Error:   "Link not found",
@@ -368,6 +583,8 @@ func DeleteLink(c *gin.Context) {
 	// Delete the link (soft delete)
 	initializers.DB.Delete(&link)
 
+	webhooks.Enqueue(link.UserID, webhooks.EventLinkDeleted, toLinkResponse(c, link))
+
 	c.JSON(http.StatusOK, dtos.SuccessResponse{
 		Success: true,
 		Data: map[string]string{
@@ -379,11 +596,14 @@ func DeleteLink(c *gin.Context) {
 // @title GetUserLinks
 // GetUserLinks godoc
 // @Summary Get user's links
-// @Description Retrieve all links created by authenticated user
+// @Description Retrieve all links created by authenticated user, optionally searched or filtered by tag or folder
 // @Tags Links
 // @Security Bearer
 // @Accept json
 // @Produce json
+// @Param q query string false "Search title, description, notes, URL and short code"
+// @Param tag query string false "Only links carrying this tag"
+// @Param folderId query int false "Only links in this folder (0 for unfiled links)"
 // @Success 200 {object} dtos.SuccessResponse{data=[]dtos.LinkResponse}
 // @Failure 401 {object} dtos.ErrorResponse
 // @Failure 500 {object} dtos.ErrorResponse
@@ -408,19 +628,47 @@ func GetUserLinks(c *gin.Context) {
 		return
 	}
 
+	query := withLinkRelations(initializers.DB).Where("links.user_id = ?", contextUser.ID)
+
+	// Optional filters
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where(
+			"links.title ILIKE ? OR links.description ILIKE ? OR links.notes ILIKE ? OR links.original_url ILIKE ? OR links.short_code ILIKE ?",
+			pattern, pattern, pattern, pattern, pattern,
+		)
+	}
+
+	if tag := c.Query("tag"); tag != "" {
+		query = query.
+			Joins("JOIN link_tags ON link_tags.link_id = links.id").
+			Joins("JOIN tags ON tags.id = link_tags.tag_id").
+			Where("tags.name = ?", tag)
+	}
+
+	folderID, hasFolder, err := parseFolderFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid folder ID",
+		})
+		return
+	}
+	if hasFolder {
+		if folderID == 0 {
+			query = query.Where("links.folder_id IS NULL")
+		} else {
+			query = query.Where("links.folder_id = ?", folderID)
+		}
+	}
+
 	var links []models.Link
-	initializers.DB.Where("user_id = ?", contextUser.ID).Find(&links)
+	query.Find(&links)
 
 	// Convert to response DTOs
 	var linkResponses []dtos.LinkResponse
 	for _, link := range links {
-		linkResponses = append(linkResponses, dtos.LinkResponse{
-			ShortCode:   link.ShortCode,
-			OriginalURL: link.OriginalURL,
-			Clicks:      link.Clicks,
-			Favicon:     link.Favicon,
-			UserID:      link.UserID,
-		})
+		linkResponses = append(linkResponses, toLinkResponse(c, link))
 	}
 
 	c.JSON(http.StatusOK, dtos.SuccessResponse{
@@ -429,6 +677,82 @@ func GetUserLinks(c *gin.Context) {
 	})
 }
 
+// Helper function: Convert a link model to its response DTO
+func toLinkResponse(c *gin.Context, link models.Link) dtos.LinkResponse {
+	aliases := []string{}
+	for _, alias := range link.Aliases {
+		aliases = append(aliases, alias.ShortCode)
+	}
+
+	tags := []string{}
+	for _, tag := range link.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	domain := ""
+	if link.Domain != nil {
+		domain = link.Domain.Hostname
+	}
+
+	urls := buildLinkURLs(c, link)
+
+	return dtos.LinkResponse{
+		ShortCode:    link.ShortCode,
+		Domain:       domain,
+		ShortURL:     urls.ShortURL,
+		PreviewURL:   urls.PreviewURL,
+		QRURL:        urls.QRURL,
+		StatsURL:     urls.StatsURL,
+		OriginalURL:  link.OriginalURL,
+		Clicks:       link.Clicks,
+		UniqueClicks: link.UniqueClicks,
+		Favicon:      link.Favicon,
+		UserID:       link.UserID,
+		Title:        link.Title,
+		Description:  link.Description,
+		Notes:        link.Notes,
+		Aliases:      aliases,
+		Tags:         tags,
+		FolderID:     link.FolderID,
+		Health: dtos.LinkHealthResponse{
+			Status:     link.HealthStatus,
+			StatusCode: link.HealthStatusCode,
+			LatencyMs:  link.HealthLatencyMs,
+			Error:      link.HealthError,
+			CheckedAt:  link.HealthCheckedAt,
+		},
+	}
+}
+
+// Helper function: Clear the health check result when a link's destination changes
+func addHealthReset(updates map[string]interface{}) {
+	updates["health_status"] = models.HealthUnknown
+	updates["health_status_code"] = 0
+	updates["health_latency_ms"] = 0
+	updates["health_error"] = ""
+	updates["health_checked_at"] = nil
+}
+
+// Helper function: Fetch the destination page's title and store it if the link still has none
+func fillLinkTitle(linkID uint, originalURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	title, err := utils.FetchPageTitle(ctx, originalURL)
+	if err != nil {
+		log.Printf("Could not fetch title for link %d: %v", linkID, err)
+		return
+	}
+
+	if len(title) > maxTitleLength {
+		title = strings.ToValidUTF8(title[:maxTitleLength], "")
+	}
+
+	initializers.DB.Model(&models.Link{}).
+		Where("id = ? AND (title = '' OR title IS NULL)", linkID).
+		Update("title", title)
+}
+
 // Helper function: Generate a random short code
 func generateShortCode() string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
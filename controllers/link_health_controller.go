@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+// GetBrokenLinks godoc
+// @Summary List broken links
+// @Description Retrieve the authenticated user's links whose destinations returned 4xx/5xx or timed out on the last health check
+// @Tags Links
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.LinkResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /links/broken [get]
+func GetBrokenLinks(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	var links []models.Link
+	err := withLinkRelations(initializers.DB).
+		Where("user_id = ? AND health_status = ?", contextUser.ID, models.HealthBroken).
+		Order("health_checked_at DESC").
+		Find(&links).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load broken links",
+		})
+		return
+	}
+
+	linkResponses := []dtos.LinkResponse{}
+	for _, link := range links {
+		linkResponses = append(linkResponses, toLinkResponse(c, link))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    linkResponses,
+	})
+}
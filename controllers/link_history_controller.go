@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/webhooks"
+	"gorm.io/gorm"
+)
+
+// GetLinkHistory godoc
+// @Summary Get link destination history
+// @Description List every previous destination of a link, newest first (owner only)
+// @Tags Links
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short code of the link"
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.LinkRevisionResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /links/{shortCode}/history [get]
+func GetLinkHistory(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	link, ok := findOwnedLink(c, c.Param("shortCode"), contextUser)
+	if !ok {
+		return
+	}
+
+	var revisions []models.LinkRevision
+	if err := initializers.DB.Where("link_id = ?", link.ID).Order("created_at DESC").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load link history",
+		})
+		return
+	}
+
+	revisionResponses := []dtos.LinkRevisionResponse{}
+	for _, revision := range revisions {
+		revisionResponses = append(revisionResponses, dtos.LinkRevisionResponse{
+			ID:          revision.ID,
+			OriginalURL: revision.OriginalURL,
+			ChangedByID: revision.ChangedByID,
+			ChangedAt:   revision.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    revisionResponses,
+	})
+}
+
+// RevertLink godoc
+// @Summary Revert a link to a previous destination
+// @Description Restore the destination stored in a revision. The current destination is recorded as a new revision first (owner only)
+// @Tags Links
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short code of the link"
+// @Param revisionId path int true "Revision to restore"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.LinkResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /links/{shortCode}/history/{revisionId}/revert [post]
+func RevertLink(c *gin.Context) {
+	revisionID, err := strconv.ParseUint(c.Param("revisionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid revision ID",
+		})
+		return
+	}
+
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	link, ok := findOwnedLink(c, c.Param("shortCode"), contextUser)
+	if !ok {
+		return
+	}
+
+	var revision models.LinkRevision
+	if err := initializers.DB.Where("id = ? AND link_id = ?", revisionID, link.ID).First(&revision).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Revision not found",
+		})
+		return
+	}
+
+	if revision.OriginalURL != link.OriginalURL {
+		err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+			if err := recordLinkRevision(tx, &link, contextUser.ID); err != nil {
+				return err
+			}
+			updates := map[string]interface{}{
+				"original_url": revision.OriginalURL,
+				"hash":         generateHash(revision.OriginalURL),
+			}
+			addHealthReset(updates)
+			return tx.Model(&link).Updates(updates).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to revert link",
+			})
+			return
+		}
+	}
+
+	webhooks.Enqueue(link.UserID, webhooks.EventLinkUpdated, toLinkResponse(c, link))
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toLinkResponse(c, link),
+	})
+}
+
+// Helper function: Store the link's current destination as a revision before it is replaced
+func recordLinkRevision(tx *gorm.DB, link *models.Link, changedByID uint) error {
+	return tx.Create(&models.LinkRevision{
+		LinkID:      link.ID,
+		OriginalURL: link.OriginalURL,
+		ChangedByID: changedByID,
+	}).Error
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/clickstream"
+)
+
+// liveHeartbeatInterval keeps idle streams open through proxies and lets clients detect dead connections.
+const liveHeartbeatInterval = 15 * time.Second
+
+// StreamLinkClicks godoc
+// @Summary Stream a link's clicks live
+// @Description Stream click events for a link as they happen using Server-Sent Events (owner only).
+// @Description Events: "click" for each redirect, "dropped" when the client fell behind and missed clicks, and "heartbeat" every 15 seconds
+// @Tags Links
+// @Security Bearer
+// @Produce text/event-stream
+// @Param shortCode path string true "Short code of the link"
+// @Param domain query string false "Custom domain the short code belongs to"
+// @Success 200 {string} string "Event stream"
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Router /links/{shortCode}/live [get]
+func StreamLinkClicks(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	link, ok := findOwnedLink(c, c.Param("shortCode"), contextUser)
+	if !ok {
+		return
+	}
+
+	streamClicks(c, clickstream.Filter{LinkID: link.ID})
+}
+
+// StreamUserClicks godoc
+// @Summary Stream all clicks live
+// @Description Stream click events for all of the authenticated user's links using Server-Sent Events.
+// @Description Events: "click" for each redirect, "dropped" when the client fell behind and missed clicks, and "heartbeat" every 15 seconds
+// @Tags Links
+// @Security Bearer
+// @Produce text/event-stream
+// @Success 200 {string} string "Event stream"
+// @Failure 401 {object} dtos.ErrorResponse
+// @Router /links/live [get]
+func StreamUserClicks(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	streamClicks(c, clickstream.Filter{UserID: contextUser.ID})
+}
+
+// Helper function: Relay clicks matching the filter to the client until it disconnects
+func streamClicks(c *gin.Context, filter clickstream.Filter) {
+	sub := clickstream.Default.Subscribe(filter)
+	defer clickstream.Default.Unsubscribe(sub)
+
+	heartbeat := time.NewTicker(liveHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stop nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+
+	// Send the headers straight away so clients know the stream is open
+	c.SSEvent("heartbeat", time.Now().UTC())
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case click, ok := <-sub.Events:
+			if !ok {
+				return false
+			}
+			if dropped := sub.TakeDropped(); dropped > 0 {
+				c.SSEvent("dropped", gin.H{"count": dropped})
+			}
+			c.SSEvent("click", click)
+			return true
+		case <-heartbeat.C:
+			if dropped := sub.TakeDropped(); dropped > 0 {
+				c.SSEvent("dropped", gin.H{"count": dropped})
+			}
+			c.SSEvent("heartbeat", time.Now().UTC())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
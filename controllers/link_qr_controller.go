@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/qrcode"
+)
+
+const (
+	// defaultQRScale is the number of pixels per QR module when ?scale is not given.
+	defaultQRScale = 8
+	// maxQRScale caps ?scale so a request can't ask for a huge image.
+	maxQRScale = 20
+)
+
+// GetLinkQRCode godoc
+// @Summary QR code of a short link
+// @Description Render the short URL as a QR code PNG, looked up on the domain in the Host header
+// @Tags Redirect
+// @Produce png
+// @Param shortCode path string true "Short code of the link"
+// @Param scale query int false "Pixels per QR module (1-20, default 8)"
+// @Success 200 {file} binary "QR code image"
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Router /{shortCode}/qr [get]
+func GetLinkQRCode(c *gin.Context) {
+	scale := defaultQRScale
+	if value := c.Query("scale"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxQRScale {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "scale must be between 1 and " + strconv.Itoa(maxQRScale),
+			})
+			return
+		}
+		scale = parsed
+	}
+
+	var link models.Link
+	err := findLinkByCode(initializers.DB.Preload("Domain"), domainIDForHost(c.Request.Host), c.Param("shortCode"), &link)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return
+	}
+
+	code, err := qrcode.Encode(buildLinkURLs(c, link).ShortURL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate QR code",
+		})
+		return
+	}
+	image, err := code.PNG(scale)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate QR code",
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "image/png", image)
+}
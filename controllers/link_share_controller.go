@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+//go:embed templates/public_stats.html
+var publicStatsHTML string
+
+var publicStatsTemplate = template.Must(template.New("public_stats").Parse(publicStatsHTML))
+
+// ShareLinkStats godoc
+// @Summary Make link statistics public
+// @Description Share a link's statistics through an unguessable public URL (owner only).
+// @Description Calling it again keeps the existing URL
+// @Tags Links
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short code of the link"
+// @Param domain query string false "Custom domain the short code belongs to"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.LinkStatsShareResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /links/{shortCode}/stats/share [post]
+func ShareLinkStats(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	link, ok := findOwnedLink(c, c.Param("shortCode"), contextUser)
+	if !ok {
+		return
+	}
+
+	if link.StatsShareToken == nil {
+		token, err := randomToken(32)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to generate share token",
+			})
+			return
+		}
+
+		if err := initializers.DB.Model(&link).Update("stats_share_token", token).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to share link statistics",
+			})
+			return
+		}
+		link.StatsShareToken = &token
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.LinkStatsShareResponse{
+			Public:   true,
+			ShareURL: publicStatsURL(c, *link.StatsShareToken),
+		},
+	})
+}
+
+// UnshareLinkStats godoc
+// @Summary Make link statistics private
+// @Description Stop sharing a link's statistics (owner only). The old public URL stops working;
+// @Description sharing again generates a new one
+// @Tags Links
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short code of the link"
+// @Param domain query string false "Custom domain the short code belongs to"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.LinkStatsShareResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /links/{shortCode}/stats/share [delete]
+func UnshareLinkStats(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	link, ok := findOwnedLink(c, c.Param("shortCode"), contextUser)
+	if !ok {
+		return
+	}
+
+	if err := initializers.DB.Model(&link).Update("stats_share_token", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to stop sharing link statistics",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    dtos.LinkStatsShareResponse{Public: false},
+	})
+}
+
+// GetPublicLinkStats godoc
+// @Summary View shared link statistics
+// @Description Read-only statistics of a link whose owner shared them. No account needed.
+// @Description Returns an HTML page, or JSON when requested with Accept: application/json or ?format=json
+// @Tags Public Stats
+// @Produce html
+// @Produce json
+// @Param token path string true "Share token"
+// @Param format query string false "Set to json for a JSON response"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.PublicLinkStatsResponse}
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /s/{token} [get]
+func GetPublicLinkStats(c *gin.Context) {
+	wantsJSON := c.Query("format") == "json" || c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON
+
+	var link models.Link
+	err := initializers.DB.Preload("Domain").Where("stats_share_token = ?", c.Param("token")).First(&link).Error
+	if err != nil {
+		if wantsJSON {
+			c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Stats not found",
+			})
+		} else {
+			c.String(http.StatusNotFound, "Stats not found")
+		}
+		return
+	}
+
+	breakdown, err := loadClickBreakdown(link.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load link statistics",
+		})
+		return
+	}
+
+	stats := dtos.PublicLinkStatsResponse{
+		ShortURL:     buildLinkURLs(c, link).ShortURL,
+		Title:        link.Title,
+		Clicks:       link.Clicks,
+		UniqueClicks: link.UniqueClicks,
+		Timeline:     breakdown.Timeline,
+		TopReferrers: breakdown.TopReferrers,
+		TopCountries: breakdown.TopCountries,
+		CreatedAt:    link.CreatedAt,
+	}
+
+	// Shared pages must not be indexed or cached by intermediaries
+	c.Header("X-Robots-Tag", "noindex")
+	c.Header("Cache-Control", "private, max-age=60")
+
+	if wantsJSON {
+		c.JSON(http.StatusOK, dtos.SuccessResponse{
+			Success: true,
+			Data:    stats,
+		})
+		return
+	}
+
+	var page bytes.Buffer
+	if err := publicStatsTemplate.Execute(&page, newPublicStatsPage(stats)); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to render statistics")
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
+
+// publicStatsPage is the data rendered by the public stats template.
+type publicStatsPage struct {
+	Stats    dtos.PublicLinkStatsResponse
+	Bars     []publicStatsBar
+	FirstDay string
+	LastDay  string
+}
+
+type publicStatsBar struct {
+	Day     string
+	Clicks  int
+	Percent int
+}
+
+// Helper function: Scale the timeline to bar heights relative to the busiest day
+func newPublicStatsPage(stats dtos.PublicLinkStatsResponse) publicStatsPage {
+	page := publicStatsPage{Stats: stats}
+
+	busiest := 0
+	for _, day := range stats.Timeline {
+		if day.Clicks > busiest {
+			busiest = day.Clicks
+		}
+	}
+
+	for _, day := range stats.Timeline {
+		percent := 0
+		if busiest > 0 {
+			percent = day.Clicks * 100 / busiest
+		}
+		page.Bars = append(page.Bars, publicStatsBar{Day: day.Day, Clicks: day.Clicks, Percent: percent})
+	}
+
+	if len(stats.Timeline) > 0 {
+		page.FirstDay = stats.Timeline[0].Day
+		page.LastDay = stats.Timeline[len(stats.Timeline)-1].Day
+	}
+
+	return page
+}
+
+// Helper function: The public stats page URL for a share token
+func publicStatsURL(c *gin.Context, token string) string {
+	return requestBaseURL(c) + "/s/" + url.PathEscape(token)
+}
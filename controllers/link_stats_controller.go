@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+// GetLinkStats godoc
+// @Summary Get link statistics
+// @Description Retrieve click statistics for a link (owner only): totals, a 30 day timeline, top referrers and countries, and a breakdown of bot traffic
+// @Tags Links
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short code of the link"
+// @Param domain query string false "Custom domain the short code belongs to"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.LinkStatsResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /links/{shortCode}/stats [get]
+func GetLinkStats(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	link, ok := findOwnedLink(c, c.Param("shortCode"), contextUser)
+	if !ok {
+		return
+	}
+
+	var botHits []models.LinkBotHit
+	if err := initializers.DB.Where("link_id = ?", link.ID).Order("hits DESC").Find(&botHits).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load link statistics",
+		})
+		return
+	}
+
+	bots := []dtos.LinkBotStats{}
+	for _, hit := range botHits {
+		bots = append(bots, dtos.LinkBotStats{
+			Name:       hit.Name,
+			Category:   hit.Category,
+			Hits:       hit.Hits,
+			LastSeenAt: hit.LastSeenAt,
+		})
+	}
+
+	breakdown, err := loadClickBreakdown(link.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load link statistics",
+		})
+		return
+	}
+
+	shareURL := ""
+	if link.StatsShareToken != nil {
+		shareURL = publicStatsURL(c, *link.StatsShareToken)
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.LinkStatsResponse{
+			ShortCode:    link.ShortCode,
+			ShortURL:     buildLinkURLs(c, link).ShortURL,
+			Clicks:       link.Clicks,
+			UniqueClicks: link.UniqueClicks,
+			BotClicks:    link.BotClicks,
+			Bots:         bots,
+			Timeline:     breakdown.Timeline,
+			TopReferrers: breakdown.TopReferrers,
+			TopCountries: breakdown.TopCountries,
+			ShareURL:     shareURL,
+			CreatedAt:    link.CreatedAt,
+		},
+	})
+}
+
+// PreviewLink godoc
+// @Summary Preview a short link
+// @Description Show where a short link goes without following it or counting a click
+// @Tags Redirect
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short code of the link"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.LinkPreviewResponse}
+// @Failure 404 {object} dtos.ErrorResponse
+// @Router /{shortCode}/preview [get]
+func PreviewLink(c *gin.Context) {
+	var link models.Link
+	err := findLinkByCode(initializers.DB.Preload("Domain"), domainIDForHost(c.Request.Host), c.Param("shortCode"), &link)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.LinkPreviewResponse{
+			ShortURL:    buildLinkURLs(c, link).ShortURL,
+			OriginalURL: link.OriginalURL,
+			Title:       link.Title,
+			Description: link.Description,
+		},
+	})
+}
+
+const (
+	// statsTimelineDays is how many days the click timeline covers.
+	statsTimelineDays = 30
+	// statsTopLimit is how many referrers and countries are listed.
+	statsTopLimit = 10
+)
+
+// clickBreakdown is the part of a link's statistics shared by the owner's and the public stats views.
+type clickBreakdown struct {
+	Timeline     []dtos.LinkClickDayStats
+	TopReferrers []dtos.LinkReferrerStats
+	TopCountries []dtos.LinkCountryStats
+}
+
+// Helper function: Load the click timeline with missing days filled in, and the top referrers and countries
+func loadClickBreakdown(linkID uint, now time.Time) (clickBreakdown, error) {
+	breakdown := clickBreakdown{
+		Timeline:     []dtos.LinkClickDayStats{},
+		TopReferrers: []dtos.LinkReferrerStats{},
+		TopCountries: []dtos.LinkCountryStats{},
+	}
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	firstDay := today.AddDate(0, 0, -(statsTimelineDays - 1))
+
+	var days []models.LinkClickDay
+	err := initializers.DB.Where("link_id = ? AND day >= ?", linkID, firstDay.Format("2006-01-02")).Find(&days).Error
+	if err != nil {
+		return breakdown, err
+	}
+
+	clicksByDay := map[string]int{}
+	for _, day := range days {
+		clicksByDay[day.Day] = day.Clicks
+	}
+	for day := firstDay; !day.After(today); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		breakdown.Timeline = append(breakdown.Timeline, dtos.LinkClickDayStats{Day: key, Clicks: clicksByDay[key]})
+	}
+
+	var referrers []models.LinkReferrer
+	err = initializers.DB.Where("link_id = ?", linkID).Order("clicks DESC, referrer").Limit(statsTopLimit).Find(&referrers).Error
+	if err != nil {
+		return breakdown, err
+	}
+	for _, referrer := range referrers {
+		breakdown.TopReferrers = append(breakdown.TopReferrers, dtos.LinkReferrerStats{Referrer: referrer.Referrer, Clicks: referrer.Clicks})
+	}
+
+	var countries []models.LinkCountry
+	err = initializers.DB.Where("link_id = ?", linkID).Order("clicks DESC, country").Limit(statsTopLimit).Find(&countries).Error
+	if err != nil {
+		return breakdown, err
+	}
+	for _, country := range countries {
+		breakdown.TopCountries = append(breakdown.TopCountries, dtos.LinkCountryStats{Country: country.Country, Clicks: country.Clicks})
+	}
+
+	return breakdown, nil
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/webhooks"
+)
+
+// GetTrashedLinks godoc
+// @Summary List deleted links
+// @Description Retrieve the authenticated user's soft-deleted links and when each will be purged
+// @Tags Links
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.TrashedLinkResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /links/trash [get]
+func GetTrashedLinks(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	var links []models.Link
+	err := withLinkRelations(initializers.DB.Unscoped()).
+		Where("user_id = ? AND deleted_at IS NOT NULL", contextUser.ID).
+		Order("deleted_at DESC").
+		Find(&links).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load deleted links",
+		})
+		return
+	}
+
+	retention := initializers.TrashRetention()
+	linkResponses := []dtos.TrashedLinkResponse{}
+	for _, link := range links {
+		linkResponses = append(linkResponses, dtos.TrashedLinkResponse{
+			LinkResponse: toLinkResponse(c, link),
+			DeletedAt:    link.DeletedAt.Time,
+			PurgeAt:      link.DeletedAt.Time.Add(retention),
+		})
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    linkResponses,
+	})
+}
+
+// RestoreLink godoc
+// @Summary Restore a deleted link
+// @Description Move a link out of the trash so it redirects again (owner only)
+// @Tags Links
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short code of the link"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.LinkResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /links/{shortCode}/restore [post]
+func RestoreLink(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	link, ok := findTrashedLink(c, c.Param("shortCode"), contextUser)
+	if !ok {
+		return
+	}
+
+	if err := initializers.DB.Unscoped().Model(&link).Update("deleted_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to restore link",
+		})
+		return
+	}
+
+	webhooks.Enqueue(link.UserID, webhooks.EventLinkUpdated, toLinkResponse(c, link))
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toLinkResponse(c, link),
+	})
+}
+
+// PurgeLink godoc
+// @Summary Permanently delete a link
+// @Description Permanently delete a link from the trash, releasing its short code (owner only)
+// @Tags Links
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short code of the link"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /links/{shortCode}/purge [delete]
+func PurgeLink(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	link, ok := findTrashedLink(c, c.Param("shortCode"), contextUser)
+	if !ok {
+		return
+	}
+
+	// Hard delete; revisions are removed by the ON DELETE CASCADE constraint
+	if err := initializers.DB.Unscoped().Delete(&link).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to permanently delete link",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Link permanently deleted",
+		},
+	})
+}
+
+// Helper function: Load a soft-deleted link owned by the user, writing the 404/403 response on failure
+func findTrashedLink(c *gin.Context, shortCode string, contextUser ContextUserStruct) (models.Link, bool) {
+	var link models.Link
+
+	domainID, ok := queryDomainID(c)
+	if !ok {
+		return link, false
+	}
+
+	result := withLinkRelations(initializers.DB.Unscoped()).
+		Where("domain_id = ? AND short_code = ? AND deleted_at IS NOT NULL", domainID, shortCode).
+		First(&link)
+
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Deleted link not found",
+		})
+		return link, false
+	}
+
+	if link.UserID != contextUser.ID {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You can only manage your own links",
+		})
+		return link, false
+	}
+
+	return link, true
+}
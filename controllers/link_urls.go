@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+// linkURLs holds the public URLs derived from a link's short code.
+type linkURLs struct {
+	ShortURL   string
+	PreviewURL string
+	QRURL      string
+	StatsURL   string
+}
+
+// buildLinkURLs returns the public URLs for a link. Links on custom domains use that domain;
+// everything else uses the configured public base URL that matches the request.
+func buildLinkURLs(c *gin.Context, link models.Link) linkURLs {
+	apiBaseURL := requestBaseURL(c)
+
+	shortBaseURL := apiBaseURL
+	if link.Domain != nil {
+		shortBaseURL = "https://" + link.Domain.Hostname
+	}
+
+	shortURL := shortBaseURL + "/" + url.PathEscape(link.ShortCode)
+
+	statsURL := apiBaseURL + "/api/v1/links/" + url.PathEscape(link.ShortCode) + "/stats"
+	if link.Domain != nil {
+		statsURL += "?domain=" + url.QueryEscape(link.Domain.Hostname)
+	}
+
+	return linkURLs{
+		ShortURL:   shortURL,
+		PreviewURL: shortURL + "/preview",
+		QRURL:      shortURL + "/qr",
+		StatsURL:   statsURL,
+	}
+}
+
+// requestBaseURL picks the configured public base URL matching the request's scheme and host.
+// X-Forwarded-Proto and X-Forwarded-Host are only honoured when the request comes from a
+// trusted proxy. Unknown hosts fall back to the first configured base URL so a spoofed
+// Host header can never leak into generated links.
+func requestBaseURL(c *gin.Context) string {
+	baseURLs := initializers.PublicBaseURLs()
+	if c == nil || c.Request == nil {
+		return baseURLs[0]
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	host := c.Request.Host
+
+	if isTrustedProxy(c.RemoteIP()) {
+		if proto := firstHeaderValue(c.GetHeader("X-Forwarded-Proto")); proto != "" {
+			scheme = strings.ToLower(proto)
+		}
+		if forwardedHost := firstHeaderValue(c.GetHeader("X-Forwarded-Host")); forwardedHost != "" {
+			host = forwardedHost
+		}
+	}
+
+	requested := scheme + "://" + strings.ToLower(host)
+	for _, baseURL := range baseURLs {
+		if strings.EqualFold(baseURL, requested) {
+			return baseURL
+		}
+	}
+
+	return baseURLs[0]
+}
+
+// Helper function: Check the direct peer address against TRUSTED_PROXIES
+func isTrustedProxy(remoteIP string) bool {
+	ip := net.ParseIP(remoteIP)
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range initializers.TrustedProxies() {
+		if strings.Contains(proxy, "/") {
+			if _, network, err := net.ParseCIDR(proxy); err == nil && network.Contains(ip) {
+				return true
+			}
+		} else if proxyIP := net.ParseIP(proxy); proxyIP != nil && proxyIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function: Take the first entry of a comma separated header set by a proxy chain
+func firstHeaderValue(value string) string {
+	if i := strings.Index(value, ","); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
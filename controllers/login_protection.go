@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/audit"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/loginguard"
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+// Helper function: Refuse a login attempt while the account has to wait after recent failures or
+// is locked out for the client IP, or the IP is, writing the 429 response. An allowed attempt is
+// counted until recordLoginFailure or releaseLoginAttempt settles it. Logins go ahead if the guard's
+// store fails, so an outage of the counters does not lock everyone out.
+func checkLoginAllowed(c *gin.Context, email string, now time.Time) (loginguard.Decision, bool) {
+	decision, err := loginguard.Default().Begin(c.Request.Context(), email, c.ClientIP(), now)
+	if err != nil {
+		log.Println("Failed to check login attempts:", err)
+		return decision, true
+	}
+	if decision.Allowed {
+		return decision, true
+	}
+
+	retryAfter := int(decision.RetryAt.Sub(now).Round(time.Second) / time.Second)
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+	message := fmt.Sprintf("Too many failed login attempts; try again in %d seconds", retryAfter)
+	if decision.Locked {
+		message = "Too many failed login attempts; login is locked until " + decision.RetryAt.UTC().Format(time.RFC3339)
+	}
+
+	c.JSON(http.StatusTooManyRequests, dtos.ErrorResponse{
+		Success: false,
+		Error:   message,
+	})
+	return decision, false
+}
+
+// Helper function: Count a failed login against the account and client IP and audit it, along with
+// any lockout it caused. userID is nil when the email has no account.
+func recordLoginFailure(c *gin.Context, email string, userID *uint, decision loginguard.Decision, reason string, now time.Time) {
+	ip := c.ClientIP()
+
+	failure, err := loginguard.Default().RecordFailure(c.Request.Context(), email, ip, decision, now)
+	if err != nil {
+		log.Println("Failed to record failed login:", err)
+	}
+
+	audit.Record(models.AuditEvent{
+		Type:   audit.EventLoginFailed,
+		UserID: userID,
+		Email:  email,
+		IP:     ip,
+		Detail: fmt.Sprintf("%s (failure %d)", reason, failure.Failures),
+	})
+
+	if failure.AccountLockedUntil != nil {
+		audit.Record(models.AuditEvent{
+			Type:   audit.EventLoginLocked,
+			UserID: userID,
+			Email:  email,
+			IP:     ip,
+			Detail: "account locked for this IP until " + failure.AccountLockedUntil.UTC().Format(time.RFC3339),
+		})
+	}
+	if failure.IPLockedUntil != nil {
+		audit.Record(models.AuditEvent{
+			Type:   audit.EventLoginLocked,
+			Email:  email,
+			IP:     ip,
+			Detail: "IP locked until " + failure.IPLockedUntil.UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+// Helper function: Stop counting a login attempt that turned out not to be a failed guess
+func releaseLoginAttempt(c *gin.Context, decision loginguard.Decision, now time.Time) {
+	if err := loginguard.Default().Release(c.Request.Context(), decision, now); err != nil {
+		log.Println("Failed to release login attempt:", err)
+	}
+}
+
+// Helper function: Forget the account's failed logins, from anywhere and from the client IP, once
+// the login succeeded
+func recordLoginSuccess(c *gin.Context, email string) {
+	if err := loginguard.Default().RecordSuccess(c.Request.Context(), email, c.ClientIP()); err != nil {
+		log.Println("Failed to reset failed logins:", err)
+	}
+}
@@ -0,0 +1,338 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/auth"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/loginguard"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// passwordResetInterval is the minimum delay between two reset emails to the same user.
+const passwordResetInterval = time.Minute
+
+// errPasswordResetTokenUsed is returned when a reset token was used by a concurrent request.
+var errPasswordResetTokenUsed = errors.New("password reset token already used")
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset token to the address if it belongs to an account.
+// @Description The response is the same whether or not the account exists
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param input body dtos.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Router /users/password/forgot [post]
+func ForgotPassword(c *gin.Context) {
+	var req dtos.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	// The account is looked up and emailed in the background, so neither the response
+	// nor how long it takes reveals which emails have accounts
+	go requestPasswordReset(requestBaseURL(c), req.Email)
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "If an account exists for " + req.Email + ", a password reset email has been sent",
+		},
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset a forgotten password
+// @Description Set a new password with the token from the reset email. The token can be used once;
+// @Description every session of the account is logged out
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param input body dtos.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/password/reset [post]
+func ResetPassword(c *gin.Context) {
+	var req dtos.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	invalidToken := dtos.ErrorResponse{
+		Success: false,
+		Error:   "Invalid or expired reset token",
+	}
+
+	var resetToken models.PasswordResetToken
+	err := initializers.DB.Where("token_hash = ?", hashToken(req.Token)).First(&resetToken).Error
+	if err != nil || resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, invalidToken)
+		return
+	}
+
+	var user models.User
+	if err := initializers.DB.First(&user, resetToken.UserID).Error; err != nil || user.DisabledAt != nil {
+		c.JSON(http.StatusBadRequest, invalidToken)
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), 10)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to hash the password",
+		})
+		return
+	}
+
+	now := time.Now()
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the first of two concurrent resets with the same token can claim it
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", resetToken.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errPasswordResetTokenUsed
+		}
+
+		// Receiving the email also proves the address belongs to the user
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"password":          string(hash),
+			"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", now),
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := expirePasswordResets(tx, user.ID, now); err != nil {
+			return err
+		}
+		return invalidateSessions(tx, user.ID, now)
+	})
+
+	if errors.Is(err, errPasswordResetTokenUsed) {
+		c.JSON(http.StatusBadRequest, invalidToken)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reset password",
+		})
+		return
+	}
+
+	// Whoever was guessing the old password no longer keeps the owner out
+	if err := loginguard.Default().Unlock(c.Request.Context(), loginguard.AccountKey(user.Email)); err != nil {
+		log.Println("Failed to clear login lockouts after password reset:", err)
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Password reset successfully; log in with your new password",
+		},
+	})
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change the password of the authenticated user. Every other session is logged out and
+// @Description a new access and refresh token are returned for this one. API keys keep working
+// @Tags Authentication
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param input body dtos.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.LoginResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/password/change [post]
+func ChangePassword(c *gin.Context) {
+	var req dtos.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	contextUser, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := initializers.DB.First(&user, contextUser.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Current password is incorrect",
+		})
+		return
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "New password must be different from the current one",
+		})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), 10)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to hash the password",
+		})
+		return
+	}
+
+	var tokens dtos.LoginResponse
+	now := time.Now()
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password", string(hash)).Error; err != nil {
+			return err
+		}
+		if err := expirePasswordResets(tx, user.ID, now); err != nil {
+			return err
+		}
+		if err := invalidateSessions(tx, user.ID, now); err != nil {
+			return err
+		}
+
+		// Keep the caller logged in with a fresh session
+		tokens, err = issueTokens(tx, user, "", "")
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to change password",
+		})
+		return
+	}
+
+	// Tokens issued in the same second as the cutoff are not caught by it, so revoke this one explicitly
+	if contextToken := getContextToken(c); contextToken.ID != "" {
+		auth.RevokeToken(contextToken.ID, user.ID, contextToken.ExpiresAt)
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    tokens,
+	})
+}
+
+// Helper function: Email a reset token if an enabled account uses the address. Failures are only logged
+func requestPasswordReset(baseURL string, email string) {
+	var user models.User
+	if err := initializers.DB.Where("email = ?", email).First(&user).Error; err != nil || user.DisabledAt != nil {
+		return
+	}
+
+	if err := sendPasswordReset(context.Background(), baseURL, user); err != nil {
+		log.Printf("Failed to send password reset to user %d: %v", user.ID, err)
+	}
+}
+
+// Helper function: Store a new reset token for the user, replacing any earlier one, and email it.
+// Requests within a minute of the previous one are ignored.
+func sendPasswordReset(ctx context.Context, baseURL string, user models.User) error {
+	now := time.Now()
+
+	var recent int64
+	initializers.DB.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND created_at > ?", user.ID, now.Add(-passwordResetInterval)).
+		Count(&recent)
+	if recent > 0 {
+		return nil
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+
+	ttl := initializers.PasswordResetTTL()
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := expirePasswordResets(tx, user.ID, now); err != nil {
+			return err
+		}
+		return tx.Create(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: hashToken(token),
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	link := ""
+	if resetURL := initializers.PasswordResetURL(); resetURL != "" {
+		separator := "?"
+		if u, err := url.Parse(resetURL); err == nil && u.RawQuery != "" {
+			separator = "&"
+		}
+		link = resetURL + separator + "token=" + url.QueryEscape(token)
+	}
+
+	data := struct {
+		Name      string
+		Link      string
+		Token     string
+		ResetURL  string
+		ExpiresIn string
+	}{
+		Name:      user.Name,
+		Link:      link,
+		Token:     token,
+		ResetURL:  baseURL + "/api/v1/users/password/reset",
+		ExpiresIn: fmt.Sprintf("%d minutes", int(ttl.Minutes())),
+	}
+
+	return sendAccountEmail(ctx, resetPasswordEmail, user.Email, data)
+}
+
+// Helper function: Make the user's outstanding reset tokens unusable
+func expirePasswordResets(tx *gorm.DB, userID uint, now time.Time) error {
+	return tx.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", now).Error
+}
@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// GetProfile godoc
+// @Summary Get profile
+// @Description Retrieve the authenticated user's profile
+// @Tags Users
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ProfileResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Router /users/me [get]
+func GetProfile(c *gin.Context) {
+	user, ok := loadProfileUser(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toProfileResponse(user),
+	})
+}
+
+// UpdateProfile godoc
+// @Summary Update profile
+// @Description Change the authenticated user's name and email. Changing the email requires the current
+// @Description password, marks the new address as unverified and sends it a verification email
+// @Tags Users
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param input body dtos.UpdateProfileRequest true "Fields to change"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ProfileResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 409 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/me [patch]
+func UpdateProfile(c *gin.Context) {
+	var req dtos.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if _, ok := requireSessionUser(c); !ok {
+		return
+	}
+
+	user, ok := loadProfileUser(c)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+
+	emailChanged := req.Email != nil && *req.Email != user.Email
+	if emailChanged {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+			c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Current password is required to change the email address",
+			})
+			return
+		}
+
+		var taken int64
+		initializers.DB.Unscoped().Model(&models.User{}).Where("email = ? AND id <> ?", *req.Email, user.ID).Count(&taken)
+		if taken > 0 {
+			c.JSON(http.StatusConflict, dtos.ErrorResponse{
+				Success: false,
+				Error:   "User with this email already exists",
+			})
+			return
+		}
+
+		updates["email"] = *req.Email
+		updates["email_verified_at"] = nil
+		updates["email_verification_sent_at"] = nil
+	}
+
+	if len(updates) > 0 {
+		err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&user).Updates(updates).Error; err != nil {
+				return err
+			}
+			// Reset emails sent to the old address must not work any more
+			if emailChanged {
+				return expirePasswordResets(tx, user.ID, time.Now())
+			}
+			return nil
+		})
+		if err != nil {
+			// Two requests may race for the same address; the unique index decides
+			if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique") {
+				c.JSON(http.StatusConflict, dtos.ErrorResponse{
+					Success: false,
+					Error:   "User with this email already exists",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to update profile",
+			})
+			return
+		}
+	}
+
+	if req.Name != nil {
+		user.Name = *req.Name
+	}
+	if emailChanged {
+		user.Email = *req.Email
+		user.EmailVerifiedAt = nil
+		if err := sendVerificationEmail(c, user); err != nil {
+			log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toProfileResponse(user),
+	})
+}
+
+// DeleteAccount godoc
+// @Summary Delete account
+// @Description Permanently delete the authenticated user's account after confirming the password.
+// @Description Depending on ACCOUNT_DELETION_POLICY the user's links are deleted (default) or kept working
+// @Description without an owner. Tags, folders, domains, webhooks, API keys and sessions are always deleted.
+// @Description Access tokens stop working immediately because the user no longer exists
+// @Tags Users
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param input body dtos.DeleteAccountRequest true "Password confirmation"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/me [delete]
+func DeleteAccount(c *gin.Context) {
+	var req dtos.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if _, ok := requireSessionUser(c); !ok {
+		return
+	}
+
+	user, ok := loadProfileUser(c)
+	if !ok {
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Password is incorrect",
+		})
+		return
+	}
+
+	policy := initializers.AccountDeletionPolicy()
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteAccount(tx, user, policy)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete account",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Account deleted",
+		},
+	})
+}
+
+// Helper function: Delete the user and everything they own. With the anonymize policy, links on the
+// default domain are kept working without an owner; links on the user's custom domains cannot outlive
+// the domains and are deleted either way.
+func deleteAccount(tx *gorm.DB, user models.User, policy string) error {
+	ownedDomains := tx.Session(&gorm.Session{NewDB: true}).
+		Model(&models.Domain{}).
+		Select("id").
+		Where("user_id = ?", user.ID)
+
+	// Hard delete; aliases, revisions and analytics are removed by the ON DELETE CASCADE constraints
+	links := tx.Unscoped().Where("user_id = ?", user.ID)
+	if policy == initializers.AccountDeletionAnonymize {
+		links = links.Where("(deleted_at IS NOT NULL OR domain_id IN (?))", ownedDomains)
+	}
+	if err := links.Delete(&models.Link{}).Error; err != nil {
+		return err
+	}
+
+	if policy == initializers.AccountDeletionAnonymize {
+		// Private notes and edit history go with the account
+		keptLinks := tx.Session(&gorm.Session{NewDB: true}).
+			Model(&models.Link{}).
+			Select("id").
+			Where("user_id = ?", user.ID)
+		if err := tx.Unscoped().Where("link_id IN (?)", keptLinks).Delete(&models.LinkRevision{}).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.Link{}).Where("user_id = ?", user.ID).Updates(map[string]interface{}{
+			"user_id":           nil,
+			"folder_id":         nil,
+			"notes":             "",
+			"stats_share_token": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	owned := []interface{}{
+		&models.Tag{},
+		&models.Folder{},
+		&models.Domain{},
+		&models.Webhook{},
+		&models.APIKey{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.PasswordResetToken{},
+		&models.RecoveryCode{},
+	}
+	for _, model := range owned {
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Unscoped().Delete(&user).Error
+}
+
+// Helper function: Load the authenticated user's record, writing the error response on failure
+func loadProfileUser(c *gin.Context) (models.User, bool) {
+	var user models.User
+
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return user, false
+	}
+
+	if err := initializers.DB.First(&user, contextUser.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+		return user, false
+	}
+
+	return user, true
+}
+
+func toProfileResponse(user models.User) dtos.ProfileResponse {
+	return dtos.ProfileResponse{
+		ID:               user.ID,
+		Name:             user.Name,
+		Email:            user.Email,
+		Role:             user.Role,
+		EmailVerified:    user.EmailVerifiedAt != nil,
+		TwoFactorEnabled: user.TOTPEnabledAt != nil,
+		ReportFrequency:  user.ReportFrequency,
+		CreatedAt:        user.CreatedAt,
+	}
+}
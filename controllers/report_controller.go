@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/mailer"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/reports"
+)
+
+// GetReportSettings godoc
+// @Summary Get report digest settings
+// @Description Retrieve how often the authenticated user receives link performance digests by email
+// @Tags Reports
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ReportSettingsResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Router /reports/settings [get]
+func GetReportSettings(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := initializers.DB.First(&user, contextUser.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toReportSettingsResponse(user),
+	})
+}
+
+// UpdateReportSettings godoc
+// @Summary Update report digest settings
+// @Description Choose to receive link performance digests daily, weekly or never.
+// @Description Daily digests cover the previous UTC day, weekly digests the previous Monday to Sunday
+// @Tags Reports
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param input body dtos.ReportSettingsRequest true "Report settings"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ReportSettingsResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /reports/settings [put]
+func UpdateReportSettings(c *gin.Context) {
+	var req dtos.ReportSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := initializers.DB.First(&user, contextUser.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+		return
+	}
+
+	if err := initializers.DB.Model(&user).Update("report_frequency", req.Frequency).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update report settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toReportSettingsResponse(user),
+	})
+}
+
+// PreviewReport godoc
+// @Summary Preview a report digest
+// @Description Build the digest for the latest complete day or week without sending it
+// @Tags Reports
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param frequency query string false "daily or weekly (default weekly)"
+// @Success 200 {object} dtos.SuccessResponse{data=reports.Report}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /reports/preview [get]
+func PreviewReport(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	frequency, ok := reportFrequencyParam(c)
+	if !ok {
+		return
+	}
+
+	report, err := reports.Build(contextUser.ID, frequency, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to build report",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// SendTestReport godoc
+// @Summary Email a report digest now
+// @Description Email the digest for the latest complete day or week to the authenticated user.
+// @Description Does not affect the regular schedule
+// @Tags Reports
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param frequency query string false "daily or weekly (default weekly)"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 502 {object} dtos.ErrorResponse
+// @Router /reports/send [post]
+func SendTestReport(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	frequency, ok := reportFrequencyParam(c)
+	if !ok {
+		return
+	}
+
+	user := models.User{Name: contextUser.Name, Email: contextUser.Email}
+	user.ID = contextUser.ID
+
+	err := reports.Send(c.Request.Context(), mailer.Default(), user, frequency, time.Now())
+	if errors.Is(err, reports.ErrNothingToReport) {
+		c.JSON(http.StatusOK, dtos.SuccessResponse{
+			Success: true,
+			Data: map[string]string{
+				"message": "Nothing to report: you have no links yet, so no email was sent",
+			},
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to send report: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Report sent to " + contextUser.Email,
+		},
+	})
+}
+
+// Helper function: Read the optional daily/weekly frequency query parameter, writing the 400 response on failure
+func reportFrequencyParam(c *gin.Context) (string, bool) {
+	frequency := c.DefaultQuery("frequency", models.ReportWeekly)
+	if frequency != models.ReportDaily && frequency != models.ReportWeekly {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "frequency must be daily or weekly",
+		})
+		return "", false
+	}
+	return frequency, true
+}
+
+func toReportSettingsResponse(user models.User) dtos.ReportSettingsResponse {
+	return dtos.ReportSettingsResponse{
+		Frequency:  user.ReportFrequency,
+		LastSentAt: user.ReportLastSentAt,
+	}
+}
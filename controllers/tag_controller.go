@@ -0,0 +1,303 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetTags godoc
+// @Summary List tags
+// @Description Retrieve the authenticated user's tags with the number of links carrying each
+// @Tags Tags
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.TagResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /tags [get]
+func GetTags(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	tagResponses := []dtos.TagResponse{}
+	err := initializers.DB.Model(&models.Tag{}).
+		Select("tags.id, tags.name, COUNT(links.id) AS link_count").
+		Joins("LEFT JOIN link_tags ON link_tags.tag_id = tags.id").
+		Joins("LEFT JOIN links ON links.id = link_tags.link_id AND links.deleted_at IS NULL").
+		Where("tags.user_id = ?", contextUser.ID).
+		Group("tags.id, tags.name").
+		Order("tags.name").
+		Scan(&tagResponses).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load tags",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    tagResponses,
+	})
+}
+
+// RenameTag godoc
+// @Summary Rename a tag
+// @Description Rename one of the authenticated user's tags. Use merge to combine with an existing tag
+// @Tags Tags
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Param input body dtos.TagRequest true "New tag name"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.TagResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 409 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /tags/{id} [patch]
+func RenameTag(c *gin.Context) {
+	var req dtos.TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	tag, ok := findOwnedTag(c, c.Param("id"), contextUser)
+	if !ok {
+		return
+	}
+
+	// Binding only checks the raw length, so "   " gets this far
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Tag name cannot be blank",
+		})
+		return
+	}
+
+	if err := initializers.DB.Model(&tag).Update("name", name).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique") {
+			c.JSON(http.StatusConflict, dtos.ErrorResponse{
+				Success: false,
+				Error:   "A tag with this name already exists, merge the tags instead",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to rename tag",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.TagResponse{
+			ID:        tag.ID,
+			Name:      tag.Name,
+			LinkCount: countTagLinks(tag.ID),
+		},
+	})
+}
+
+// MergeTag godoc
+// @Summary Merge a tag into another
+// @Description Move every link from the source tag to the target tag, then delete the source tag
+// @Tags Tags
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Source tag ID"
+// @Param input body dtos.MergeTagRequest true "Target tag"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.TagResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /tags/{id}/merge [post]
+func MergeTag(c *gin.Context) {
+	var req dtos.MergeTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	source, ok := findOwnedTag(c, c.Param("id"), contextUser)
+	if !ok {
+		return
+	}
+
+	target, ok := findOwnedTag(c, strconv.FormatUint(uint64(req.TargetTagID), 10), contextUser)
+	if !ok {
+		return
+	}
+
+	if source.ID == target.ID {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Cannot merge a tag into itself",
+		})
+		return
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Tag every link of the source with the target, skipping links that already have both
+		err := tx.Exec(
+			`INSERT INTO link_tags (link_id, tag_id)
+			 SELECT link_id, ? FROM link_tags WHERE tag_id = ?
+			 ON CONFLICT DO NOTHING`,
+			target.ID, source.ID,
+		).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&source).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to merge tags",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.TagResponse{
+			ID:        target.ID,
+			Name:      target.Name,
+			LinkCount: countTagLinks(target.ID),
+		},
+	})
+}
+
+// DeleteTag godoc
+// @Summary Delete a tag
+// @Description Delete a tag and detach it from all links. The links themselves are kept
+// @Tags Tags
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /tags/{id} [delete]
+func DeleteTag(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	tag, ok := findOwnedTag(c, c.Param("id"), contextUser)
+	if !ok {
+		return
+	}
+
+	// Hard delete; link_tags rows are removed by the ON DELETE CASCADE constraint
+	if err := initializers.DB.Unscoped().Delete(&tag).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete tag",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Tag deleted successfully",
+		},
+	})
+}
+
+// Helper function: Load a tag by ID scoped to the user, writing the 404 response on failure
+func findOwnedTag(c *gin.Context, id string, contextUser ContextUserStruct) (models.Tag, bool) {
+	var tag models.Tag
+	result := initializers.DB.Where("id = ? AND user_id = ?", id, contextUser.ID).First(&tag)
+
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Tag not found",
+		})
+		return tag, false
+	}
+
+	return tag, true
+}
+
+// Helper function: Find the user's tags by name, creating any that do not exist yet
+func resolveTags(tx *gorm.DB, userID uint, names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	seen := map[string]bool{}
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		tag := models.Tag{UserID: userID, Name: name}
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag).Error
+		if err != nil {
+			return nil, err
+		}
+
+		// ON CONFLICT DO NOTHING leaves the ID unset when the tag already existed
+		if tag.ID == 0 {
+			if err := tx.Where("user_id = ? AND name = ?", userID, name).First(&tag).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// Helper function: Count the live links carrying a tag
+func countTagLinks(tagID uint) int64 {
+	var count int64
+	initializers.DB.Table("link_tags").
+		Joins("JOIN links ON links.id = link_tags.link_id AND links.deleted_at IS NULL").
+		Where("link_tags.tag_id = ?", tagID).
+		Count(&count)
+	return count
+}
@@ -0,0 +1,367 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/olujimiAdebakin/Shurl/auth"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"gorm.io/gorm"
+)
+
+// errRefreshTokenReused is returned when a refresh token is presented a second time.
+var errRefreshTokenReused = errors.New("refresh token reuse detected")
+
+// RefreshToken godoc
+// @Summary Refresh the access token
+// @Description Exchange a refresh token for a new access token and a new refresh token.
+// @Description Refresh tokens are single use: presenting one twice revokes every token issued since the login it came from
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param input body dtos.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.LoginResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/refresh [post]
+func RefreshToken(c *gin.Context) {
+	var req dtos.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	var stored models.RefreshToken
+	if err := initializers.DB.Where("token_hash = ?", hashToken(req.RefreshToken)).First(&stored).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid refresh token",
+		})
+		return
+	}
+
+	if stored.RevokedAt != nil {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Refresh token has been revoked; please log in again",
+		})
+		return
+	}
+
+	if stored.UsedAt != nil {
+		revokeTokenFamily(stored.FamilyID)
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Refresh token has already been used; please log in again",
+		})
+		return
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Refresh token expired; please log in again",
+		})
+		return
+	}
+
+	var user models.User
+	if err := initializers.DB.First(&user, stored.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized - User not found",
+		})
+		return
+	}
+
+	if user.DisabledAt != nil {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "This account has been disabled",
+		})
+		return
+	}
+
+	var response dtos.LoginResponse
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the first of two concurrent refreshes with the same token can mark it used
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", stored.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRefreshTokenReused
+		}
+
+		var err error
+		response, err = issueTokens(tx, user, stored.FamilyID, stored.Scopes)
+		return err
+	})
+
+	if errors.Is(err, errRefreshTokenReused) {
+		revokeTokenFamily(stored.FamilyID)
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Refresh token has already been used; please log in again",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to refresh token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    response,
+	})
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revoke the access token used for this request and the refresh token of the same session
+// @Tags Authentication
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/logout [post]
+func Logout(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	// Only tokens with an ID can be revoked on their own; say so rather than pretend
+	contextToken := getContextToken(c)
+	if contextToken.APIKeyID != 0 {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "API keys cannot log out; revoke the key with DELETE /api/v1/api-keys/:id instead",
+		})
+		return
+	}
+	if contextToken.ID == "" {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "This token cannot be revoked on its own; use POST /api/v1/users/logout-all to end all sessions",
+		})
+		return
+	}
+
+	if err := auth.RevokeToken(contextToken.ID, contextUser.ID, contextToken.ExpiresAt); err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to log out",
+		})
+		return
+	}
+	if contextToken.SessionID != "" {
+		revokeTokenFamily(contextToken.SessionID)
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Logged out successfully",
+		},
+	})
+}
+
+// LogoutAll godoc
+// @Summary Log out of all sessions
+// @Description Revoke every access token and refresh token of the authenticated user, on all devices
+// @Tags Authentication
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/logout-all [post]
+func LogoutAll(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		return invalidateSessions(tx, contextUser.ID, time.Now())
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to log out of all sessions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Logged out of all sessions",
+		},
+	})
+}
+
+// Helper function: The access token details attached by the auth middleware, zero if absent
+func getContextToken(c *gin.Context) ContextTokenStruct {
+	value, _ := c.Get("token")
+	contextToken, _ := value.(ContextTokenStruct)
+	return contextToken
+}
+
+// Helper function: Sign an access token and store a new refresh token for the user.
+// An empty familyID starts a new family, as on login; empty scopes grant the user's default scopes.
+func issueTokens(tx *gorm.DB, user models.User, familyID string, scopes string) (dtos.LoginResponse, error) {
+	now := time.Now()
+	accessExpiresAt := now.Add(initializers.AccessTokenTTL())
+
+	// Restricted sessions never exceed what the user may currently hold, e.g. after losing the admin role
+	defaultScopes := auth.DefaultScopes(user.Role)
+	granted := defaultScopes
+	if scopes != "" {
+		granted = []string{}
+		for _, scope := range auth.SplitScopes(scopes) {
+			if auth.HasScope(defaultScopes, scope) {
+				granted = append(granted, scope)
+			}
+		}
+	}
+
+	var err error
+	if familyID == "" {
+		if familyID, err = randomToken(16); err != nil {
+			return dtos.LoginResponse{}, err
+		}
+	}
+
+	jti, err := randomToken(16)
+	if err != nil {
+		return dtos.LoginResponse{}, err
+	}
+
+	// jti identifies the token for logout; sid ties it to its refresh token family; scope is space separated.
+	// iat has microseconds, so a token issued just after logging out of all sessions is told apart from one just before
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": user.ID,
+		"jti": jti,
+		"sid": familyID,
+		"scope": strings.Join(granted, " "),
+		"iat": float64(now.UnixMicro()) / 1e6,
+		"exp": accessExpiresAt.Unix(),
+	})
+
+	accessToken, err := token.SignedString([]byte(os.Getenv("SECRET_KEY")))
+	if err != nil {
+		return dtos.LoginResponse{}, err
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return dtos.LoginResponse{}, err
+	}
+
+	stored := models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		Scopes:    scopes,
+		ExpiresAt: now.Add(initializers.RefreshTokenTTL()),
+	}
+	if err := tx.Create(&stored).Error; err != nil {
+		return dtos.LoginResponse{}, err
+	}
+
+	return dtos.LoginResponse{
+		ID:                    user.ID,
+		Name:                  user.Name,
+		Email:                 user.Email,
+		EmailVerified:         user.EmailVerifiedAt != nil,
+		Token:                 accessToken,
+		TokenType:             "Bearer",
+		ExpiresAt:             accessExpiresAt,
+		ExpiresIn:             int64(accessExpiresAt.Sub(now).Seconds()),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: stored.ExpiresAt,
+		Scopes:                granted,
+	}, nil
+}
+
+// Helper function: Check scopes requested for a token or API key, writing the error response on failure.
+// Only admins may request the admin scope.
+func validateRequestedScopes(c *gin.Context, scopes []string, role string) bool {
+	for _, scope := range scopes {
+		if !auth.IsValidScope(scope) {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Unknown scope: " + scope,
+			})
+			return false
+		}
+		if scope == auth.ScopeAdmin && role != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Only admins can be granted the admin scope",
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// Helper function: End every session of the user. Access tokens issued before now are rejected;
+// refresh tokens are revoked outright. API keys are not affected.
+func invalidateSessions(tx *gorm.DB, userID uint, now time.Time) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("tokens_valid_after", now).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
+
+// Helper function: Revoke every live refresh token of a family, on logout or after a token was replayed
+func revokeTokenFamily(familyID string) {
+	initializers.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now())
+}
+
+// Helper function: URL-safe random string with the given number of random bytes
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Helper function: Tokens are stored as SHA-256 so a database leak does not expose them
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
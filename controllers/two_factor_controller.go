@@ -0,0 +1,662 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/auth"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/qrcode"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// errChallengeUsed aborts a two-factor login whose challenge was consumed by a concurrent request.
+var errChallengeUsed = errors.New("two-factor challenge already used")
+
+const (
+	// twoFactorChallengeTTL is how long the user has to enter a code after their password.
+	twoFactorChallengeTTL = 5 * time.Minute
+
+	// maxTwoFactorAttempts is how many invalid codes are accepted before the password must be entered again.
+	maxTwoFactorAttempts = 5
+
+	// recoveryCodeCount is how many recovery codes are generated at a time.
+	recoveryCodeCount = 10
+
+	// enrollQRScale is the pixels per module of the enrolment QR code.
+	enrollQRScale = 4
+)
+
+// GetTwoFactorStatus godoc
+// @Summary Get two-factor authentication status
+// @Description Report whether two-factor authentication is enabled and how many recovery codes are left
+// @Tags Two-Factor Authentication
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.TwoFactorStatusResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Router /users/2fa [get]
+func GetTwoFactorStatus(c *gin.Context) {
+	user, ok := loadProfileUser(c)
+	if !ok {
+		return
+	}
+
+	var remaining int64
+	initializers.DB.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user.ID).Count(&remaining)
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.TwoFactorStatusResponse{
+			Enabled:                user.TOTPEnabledAt != nil,
+			EnabledAt:              user.TOTPEnabledAt,
+			RecoveryCodesRemaining: remaining,
+		},
+	})
+}
+
+// EnrollTwoFactor godoc
+// @Summary Start two-factor enrolment
+// @Description Generate a new TOTP secret after confirming the password. Scan the returned QR code (or enter
+// @Description the secret) in an authenticator app, then confirm with a code to turn two-factor authentication
+// @Description on. Enrolling again before confirming replaces the secret
+// @Tags Two-Factor Authentication
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param input body dtos.EnrollTwoFactorRequest true "Current password"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.TwoFactorEnrollResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 409 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/2fa/enroll [post]
+func EnrollTwoFactor(c *gin.Context) {
+	var req dtos.EnrollTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if _, ok := requireSessionUser(c); !ok {
+		return
+	}
+
+	user, ok := loadProfileUser(c)
+	if !ok {
+		return
+	}
+
+	if user.TOTPEnabledAt != nil {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Two-factor authentication is already enabled; disable it first to enrol a new device",
+		})
+		return
+	}
+
+	if !checkTwoFactorPassword(c, user, req.Password) {
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate secret",
+		})
+		return
+	}
+
+	encrypted, err := auth.EncryptSecret(secret)
+	if err == nil {
+		err = initializers.DB.Model(&user).Updates(map[string]interface{}{
+			"totp_secret":    encrypted,
+			"totp_last_step": 0,
+		}).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to start enrolment",
+		})
+		return
+	}
+
+	uri := auth.TOTPURI(secret, initializers.TOTPIssuer(), user.Email)
+	qrCode, err := qrCodeDataURI(uri)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate QR code",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.TwoFactorEnrollResponse{
+			Secret:     secret,
+			OTPAuthURI: uri,
+			QRCode:     qrCode,
+		},
+	})
+}
+
+// ConfirmTwoFactor godoc
+// @Summary Confirm two-factor enrolment
+// @Description Turn two-factor authentication on with the password and a code from the authenticator app.
+// @Description Returns recovery codes, which are only shown this once
+// @Tags Two-Factor Authentication
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param input body dtos.ConfirmTwoFactorRequest true "Password and code from the authenticator app"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.RecoveryCodesResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 409 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/2fa/confirm [post]
+func ConfirmTwoFactor(c *gin.Context) {
+	var req dtos.ConfirmTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if _, ok := requireSessionUser(c); !ok {
+		return
+	}
+
+	user, ok := loadProfileUser(c)
+	if !ok {
+		return
+	}
+
+	if user.TOTPEnabledAt != nil {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Two-factor authentication is already enabled",
+		})
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Start enrolment first",
+		})
+		return
+	}
+
+	if !checkTwoFactorPassword(c, user, req.Password) {
+		return
+	}
+
+	secret, err := auth.DecryptSecret(user.TOTPSecret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to read secret; enrol again",
+		})
+		return
+	}
+
+	now := time.Now()
+	step, valid := auth.VerifyTOTP(secret, req.Code, now, user.TOTPLastStep)
+	if !valid {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid code",
+		})
+		return
+	}
+
+	var codes []string
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled_at":      now,
+			"totp_last_step":       step,
+			"totp_failed_attempts": 0,
+		}).Error; err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to enable two-factor authentication",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    dtos.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off with the password and a code from the authenticator app
+// @Description or a recovery code. The secret and recovery codes are deleted
+// @Tags Two-Factor Authentication
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param input body dtos.DisableTwoFactorRequest true "Password and code"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/2fa/disable [post]
+func DisableTwoFactor(c *gin.Context) {
+	var req dtos.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if _, ok := requireSessionUser(c); !ok {
+		return
+	}
+
+	user, ok := loadProfileUser(c)
+	if !ok {
+		return
+	}
+
+	if user.TOTPEnabledAt == nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Two-factor authentication is not enabled",
+		})
+		return
+	}
+
+	if !checkTwoFactorPassword(c, user, req.Password) {
+		return
+	}
+
+	if !checkSecondFactor(c, user, req.Code) {
+		return
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_secret":          "",
+			"totp_enabled_at":      nil,
+			"totp_last_step":       0,
+			"totp_failed_attempts": 0,
+			"totp_challenge_hash":  "",
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to disable two-factor authentication",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Two-factor authentication disabled",
+		},
+	})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes with new ones after confirming a code. The old codes stop working
+// @Tags Two-Factor Authentication
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param input body dtos.TwoFactorCodeRequest true "Code from the authenticator app or a recovery code"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.RecoveryCodesResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/2fa/recovery-codes [post]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var req dtos.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if _, ok := requireSessionUser(c); !ok {
+		return
+	}
+
+	user, ok := loadProfileUser(c)
+	if !ok {
+		return
+	}
+
+	if user.TOTPEnabledAt == nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Two-factor authentication is not enabled",
+		})
+		return
+	}
+
+	if !checkSecondFactor(c, user, req.Code) {
+		return
+	}
+
+	var codes []string
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate recovery codes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    dtos.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// VerifyTwoFactorLogin godoc
+// @Summary Complete a two-factor login
+// @Description Exchange the challenge token returned by login and a code from the authenticator app (or a
+// @Description recovery code) for an access and refresh token. After 5 invalid codes the password must be
+// @Description entered again
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param input body dtos.TwoFactorLoginRequest true "Challenge token and code"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.LoginResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 429 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /users/login/2fa [post]
+func VerifyTwoFactorLogin(c *gin.Context) {
+	var req dtos.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	invalidChallenge := dtos.ErrorResponse{
+		Success: false,
+		Error:   "Invalid or expired login challenge; log in again",
+	}
+
+	// The subject is "<user ID>:<nonce>:<requested scopes>"
+	subject, err := auth.VerifySignedToken(auth.PurposeTwoFactorChallenge, req.ChallengeToken, time.Now())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, invalidChallenge)
+		return
+	}
+	userID, rest, _ := strings.Cut(subject, ":")
+	nonce, scopes, _ := strings.Cut(rest, ":")
+	id, _ := strconv.ParseUint(userID, 10, 64)
+
+	// Only the latest challenge is outstanding, and it is cleared once used
+	var user models.User
+	err = initializers.DB.First(&user, id).Error
+	if err != nil || user.TOTPEnabledAt == nil || nonce == "" || user.TOTPChallengeHash != hashToken(nonce) {
+		c.JSON(http.StatusUnauthorized, invalidChallenge)
+		return
+	}
+
+	if user.DisabledAt != nil {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "This account has been disabled",
+		})
+		return
+	}
+
+	now := time.Now()
+	loginAttempt, allowed := checkLoginAllowed(c, user.Email, now)
+	if !allowed {
+		return
+	}
+
+	// Count the attempt before checking the code, so concurrent guesses cannot exceed the limit
+	attempt := initializers.DB.Model(&models.User{}).
+		Where("id = ? AND totp_failed_attempts < ?", user.ID, maxTwoFactorAttempts).
+		UpdateColumn("totp_failed_attempts", gorm.Expr("totp_failed_attempts + 1"))
+	if attempt.Error != nil {
+		releaseLoginAttempt(c, loginAttempt, now)
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to verify code",
+		})
+		return
+	}
+	if attempt.RowsAffected == 0 {
+		releaseLoginAttempt(c, loginAttempt, now)
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Too many invalid codes; log in again",
+		})
+		return
+	}
+
+	if !checkSecondFactor(c, user, req.Code) {
+		recordLoginFailure(c, user.Email, &user.ID, loginAttempt, "invalid two-factor code", now)
+		return
+	}
+	releaseLoginAttempt(c, loginAttempt, now)
+
+	var tokens dtos.LoginResponse
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Consume the challenge; a concurrent request with the same token gets nothing
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND totp_challenge_hash = ?", user.ID, hashToken(nonce)).
+			UpdateColumns(map[string]interface{}{
+				"totp_challenge_hash":  "",
+				"totp_failed_attempts": 0,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errChallengeUsed
+		}
+
+		tokens, err = issueTokens(tx, user, "", scopes)
+		return err
+	})
+	if errors.Is(err, errChallengeUsed) {
+		c.JSON(http.StatusUnauthorized, invalidChallenge)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create token",
+		})
+		return
+	}
+	recordLoginSuccess(c, user.Email)
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    tokens,
+	})
+}
+
+// Helper function: Answer a correct password for an account with two-factor authentication enabled.
+// The challenge token carries a single-use nonce and the requested scopes to the second step.
+func startTwoFactorChallenge(c *gin.Context, user models.User, scopes string) {
+	nonce, err := randomToken(16)
+
+	// Each password login allows a fresh set of attempts; brute forcing the code means knowing the password.
+	// Storing the new nonce also invalidates any earlier challenge
+	if err == nil {
+		err = initializers.DB.Model(&user).UpdateColumns(map[string]interface{}{
+			"totp_failed_attempts": 0,
+			"totp_challenge_hash":  hashToken(nonce),
+		}).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to start two-factor login",
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(twoFactorChallengeTTL)
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    auth.SignToken(auth.PurposeTwoFactorChallenge, fmt.Sprintf("%d:%s:%s", user.ID, nonce, scopes), expiresAt),
+			ExpiresAt:         expiresAt,
+		},
+	})
+}
+
+// Helper function: Check the account password before changing two-factor settings, writing the 401 response on failure
+func checkTwoFactorPassword(c *gin.Context, user models.User, password string) bool {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Password is incorrect",
+		})
+		return false
+	}
+	return true
+}
+
+// Helper function: Render text as a QR code PNG data URI
+func qrCodeDataURI(text string) (string, error) {
+	code, err := qrcode.Encode(text)
+	if err != nil {
+		return "", err
+	}
+	image, err := code.PNG(enrollQRScale)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(image), nil
+}
+
+// Helper function: Accept a code from the authenticator app or an unused recovery code, writing the
+// error response when it is invalid. Either kind of code is consumed so it cannot be replayed.
+func checkSecondFactor(c *gin.Context, user models.User, code string) bool {
+	valid, err := verifySecondFactor(user, code, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to verify code",
+		})
+		return false
+	}
+	if !valid {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid code",
+		})
+		return false
+	}
+	return true
+}
+
+func verifySecondFactor(user models.User, code string, now time.Time) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	// Authenticator codes are all digits; anything else can only be a recovery code
+	if len(code) == auth.TOTPDigits && strings.Trim(code, "0123456789") == "" {
+		secret, err := auth.DecryptSecret(user.TOTPSecret)
+		if err != nil {
+			return false, err
+		}
+
+		step, valid := auth.VerifyTOTP(secret, code, now, user.TOTPLastStep)
+		if !valid {
+			return false, nil
+		}
+
+		// Only the first of two concurrent requests with the same code can claim its time step
+		result := initializers.DB.Model(&models.User{}).
+			Where("id = ? AND totp_last_step < ?", user.ID, step).
+			Update("totp_last_step", step)
+		return result.RowsAffected > 0, result.Error
+	}
+
+	result := initializers.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashToken(auth.NormalizeRecoveryCode(code))).
+		Update("used_at", now)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Helper function: Replace the user's recovery codes with a new set and return them in plain text
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := auth.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		rows = append(rows, models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: hashToken(auth.NormalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
@@ -1,13 +1,13 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/olujimiAdebakin/Shurl/auth"
 	"github.com/olujimiAdebakin/Shurl/dtos"
 	"github.com/olujimiAdebakin/Shurl/initializers"
 	"github.com/olujimiAdebakin/Shurl/models"
@@ -15,10 +15,32 @@ import (
 )
 
 type ContextUserStruct struct {
-	ID    uint   `json:"id"`
-	Email string `json:"email"`
-	Name  string `json:"name"`
-	Role  string `json:"role"`
+	ID            uint   `json:"id"`
+	Email         string `json:"email"`
+	Name          string `json:"name"`
+	Role          string `json:"role"`
+	EmailVerified bool   `json:"emailVerified"`
+}
+
+// ContextTokenStruct describes the credential a request was authenticated with:
+// an access token (JWT) or an API key.
+type ContextTokenStruct struct {
+	// ID is the token's jti claim, empty for API keys and tokens issued before jti was added.
+	ID string
+	// SessionID identifies the login the token descends from (its refresh token family).
+	SessionID string
+	// ExpiresAt is zero for API keys that never expire.
+	ExpiresAt time.Time
+	// APIKeyID is set when the request used an API key instead of an access token.
+	APIKeyID uint
+	// Scopes granted to the credential. Access tokens issued before scopes were added get the
+	// user's default scopes.
+	Scopes []string
+}
+
+// HasScope reports whether the credential grants scope.
+func (t ContextTokenStruct) HasScope(scope string) bool {
+	return auth.HasScope(t.Scopes, scope)
 }
 
 // SignUpWithToken godoc
@@ -81,13 +103,13 @@ func SignUpWithToken(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": user.ID,
-		"exp": time.Now().Add(time.Hour * 24 * 30).Unix(),
-	})
+	// A failed email does not fail signup; the user can ask for another one
+	if err := sendVerificationEmail(c, user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
 
-	tokenString, err := token.SignedString([]byte(os.Getenv("SECRET_KEY")))
+	// Generate an access token and start a new refresh token family
+	tokens, err := issueTokens(initializers.DB, user, "", "")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
 			Success: false,
@@ -99,19 +121,18 @@ func SignUpWithToken(c *gin.Context) {
 	// Return success response
 	c.JSON(http.StatusCreated, dtos.SuccessResponse{
 		Success: true,
-		Data: dtos.LoginResponse{
-			ID:    user.ID,
-			Name:  user.Name,
-			Email: user.Email,
-			Token: tokenString,
-		},
+		Data:    tokens,
 	})
 }
 
 
 // LoginWithToken godoc
 // @Summary Authenticate user and receive JWT token
-// @Description Login with email and password to receive JWT token
+// @Description Login with email and password to receive JWT token.
+// @Description Pass scopes to receive a restricted token, e.g. ["links:read","analytics:read"] for a read-only dashboard.
+// @Description When two-factor authentication is enabled the response is a dtos.TwoFactorChallengeResponse instead;
+// @Description send its challengeToken with a code to POST /users/login/2fa to receive the tokens.
+// @Description Repeated failures delay further attempts and then lock the account or IP for a while (429 with Retry-After)
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -119,6 +140,8 @@ func SignUpWithToken(c *gin.Context) {
 // @Success 200 {object} dtos.SuccessResponse{data=dtos.LoginResponse}
 // @Failure 400 {object} dtos.ErrorResponse
 // @Failure 401 {object} dtos.ErrorResponse
+// @Failure 403 {object} dtos.ErrorResponse
+// @Failure 429 {object} dtos.ErrorResponse
 // @Failure 500 {object} dtos.ErrorResponse
 // @Router /users/login [post]
 func LoginWithToken(c *gin.Context) {
@@ -133,11 +156,19 @@ func LoginWithToken(c *gin.Context) {
 		return
 	}
 
+	// Refuse guesses while the account or IP is locked out or waiting after recent failures
+	now := time.Now()
+	attempt, allowed := checkLoginAllowed(c, req.Email, now)
+	if !allowed {
+		return
+	}
+
 	// Look up user by email
 	var user models.User
 	result := initializers.DB.Where("email = ?", req.Email).First(&user)
 
 	if result.Error != nil {
+		recordLoginFailure(c, req.Email, nil, attempt, "unknown email", now)
 		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
 			Success: false,
 			Error:   "Invalid email or password",
@@ -148,20 +179,38 @@ func LoginWithToken(c *gin.Context) {
 	// Compare password with stored hash
 	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
+		recordLoginFailure(c, req.Email, &user.ID, attempt, "wrong password", now)
 		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
 			Success: false,
 			Error:   "Invalid email or password",
 		})
 		return
 	}
+	releaseLoginAttempt(c, attempt, now)
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": user.ID,
-		"exp": time.Now().Add(time.Hour * 24 * 30).Unix(),
-	})
+	if user.DisabledAt != nil {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "This account has been disabled",
+		})
+		return
+	}
+
+	// Optionally narrow the session, e.g. read-only credentials for a dashboard
+	if !validateRequestedScopes(c, req.Scopes, user.Role) {
+		return
+	}
+
+	// With two-factor authentication the password only earns a challenge; tokens come after the code.
+	// Failed logins are only forgotten then, so code guesses keep counting towards a lockout.
+	if user.TOTPEnabledAt != nil {
+		startTwoFactorChallenge(c, user, auth.JoinScopes(req.Scopes))
+		return
+	}
+	recordLoginSuccess(c, req.Email)
 
-	tokenString, err := token.SignedString([]byte(os.Getenv("SECRET_KEY")))
+	// Generate an access token and start a new refresh token family
+	tokens, err := issueTokens(initializers.DB, user, "", auth.JoinScopes(req.Scopes))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
 			Success: false,
@@ -173,12 +222,7 @@ func LoginWithToken(c *gin.Context) {
 	// Return success response
 	c.JSON(http.StatusOK, dtos.SuccessResponse{
 		Success: true,
-		Data: dtos.LoginResponse{
-			ID:    user.ID,
-			Name:  user.Name,
-			Email: user.Email,
-			Token: tokenString,
-		},
+		Data:    tokens,
 	})
 }
 
@@ -0,0 +1,406 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/webhooks"
+)
+
+// webhookLogLimit caps how many deliveries the delivery log returns.
+const webhookLogLimit = 100
+
+// GetWebhooks godoc
+// @Summary List webhooks
+// @Description Retrieve the authenticated user's webhook endpoints
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.WebhookResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /webhooks [get]
+func GetWebhooks(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	var hooks []models.Webhook
+	if err := initializers.DB.Where("user_id = ?", contextUser.ID).Order("created_at").Find(&hooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load webhooks",
+		})
+		return
+	}
+
+	webhookResponses := []dtos.WebhookResponse{}
+	for _, hook := range hooks {
+		webhookResponses = append(webhookResponses, toWebhookResponse(hook))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    webhookResponses,
+	})
+}
+
+// CreateWebhook godoc
+// @Summary Register a webhook
+// @Description Register an endpoint to receive HMAC-signed event payloads. The signing secret is only returned once
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param input body dtos.CreateWebhookRequest true "Webhook details"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.WebhookResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	var req dtos.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if !validateWebhookEvents(c, req.Events) {
+		return
+	}
+
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := randomToken(32)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to generate webhook secret",
+			})
+			return
+		}
+		secret = "whsec_" + generated
+	}
+
+	hook := models.Webhook{
+		UserID: contextUser.ID,
+		URL:    req.URL,
+		Secret: secret,
+		Events: strings.Join(req.Events, ","),
+		Active: true,
+	}
+
+	if err := initializers.DB.Create(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create webhook",
+		})
+		return
+	}
+
+	response := toWebhookResponse(hook)
+	response.Secret = hook.Secret
+
+	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+		Success: true,
+		Data:    response,
+	})
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook
+// @Description Change a webhook's URL or events, or pause and resume it
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param input body dtos.UpdateWebhookRequest true "Webhook changes"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.WebhookResponse}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /webhooks/{id} [patch]
+func UpdateWebhook(c *gin.Context) {
+	var req dtos.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Events != nil && !validateWebhookEvents(c, req.Events) {
+		return
+	}
+
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	hook, ok := findOwnedWebhook(c, c.Param("id"), contextUser)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.URL != "" {
+		updates["url"] = req.URL
+	}
+	if req.Events != nil {
+		updates["events"] = strings.Join(req.Events, ",")
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+
+	if len(updates) > 0 {
+		if err := initializers.DB.Model(&hook).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to update webhook",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toWebhookResponse(hook),
+	})
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Delete a webhook together with its delivery log
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} dtos.SuccessResponse{data=map[string]string}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /webhooks/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	hook, ok := findOwnedWebhook(c, c.Param("id"), contextUser)
+	if !ok {
+		return
+	}
+
+	// Hard delete; deliveries are removed by the ON DELETE CASCADE constraint
+	if err := initializers.DB.Unscoped().Delete(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete webhook",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: map[string]string{
+			"message": "Webhook deleted successfully",
+		},
+	})
+}
+
+// GetWebhookDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Retrieve the most recent deliveries of a webhook, newest first
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param status query string false "Only deliveries with this status (pending, succeeded, failed)"
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.WebhookDeliveryResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /webhooks/{id}/deliveries [get]
+func GetWebhookDeliveries(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	hook, ok := findOwnedWebhook(c, c.Param("id"), contextUser)
+	if !ok {
+		return
+	}
+
+	query := initializers.DB.Where("webhook_id = ?", hook.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC").Limit(webhookLogLimit).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load deliveries",
+		})
+		return
+	}
+
+	deliveryResponses := []dtos.WebhookDeliveryResponse{}
+	for _, delivery := range deliveries {
+		deliveryResponses = append(deliveryResponses, toWebhookDeliveryResponse(delivery))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    deliveryResponses,
+	})
+}
+
+// RedeliverWebhook godoc
+// @Summary Redeliver a webhook payload
+// @Description Queue a new delivery of a previous payload, e.g. after fixing the receiving endpoint
+// @Tags Webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery to resend"
+// @Success 202 {object} dtos.SuccessResponse{data=dtos.WebhookDeliveryResponse}
+// @Failure 401 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 409 {object} dtos.ErrorResponse
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func RedeliverWebhook(c *gin.Context) {
+	contextUser, ok := getContextUser(c)
+	if !ok {
+		return
+	}
+
+	hook, ok := findOwnedWebhook(c, c.Param("id"), contextUser)
+	if !ok {
+		return
+	}
+
+	if !hook.Active {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Webhook is inactive; activate it before redelivering",
+		})
+		return
+	}
+
+	var original models.WebhookDelivery
+	if err := initializers.DB.Where("id = ? AND webhook_id = ?", c.Param("deliveryId"), hook.ID).First(&original).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Delivery not found",
+		})
+		return
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookID:     hook.ID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+
+	if err := initializers.DB.Create(&delivery).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to queue redelivery",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dtos.SuccessResponse{
+		Success: true,
+		Data:    toWebhookDeliveryResponse(delivery),
+	})
+}
+
+// Helper function: Load a webhook by ID scoped to the user, writing the 404 response on failure
+func findOwnedWebhook(c *gin.Context, id string, contextUser ContextUserStruct) (models.Webhook, bool) {
+	var hook models.Webhook
+	result := initializers.DB.Where("id = ? AND user_id = ?", id, contextUser.ID).First(&hook)
+
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Webhook not found",
+		})
+		return hook, false
+	}
+
+	return hook, true
+}
+
+// Helper function: Reject unknown event types, writing the 400 response
+func validateWebhookEvents(c *gin.Context, events []string) bool {
+	for _, event := range events {
+		if !webhooks.IsValidEvent(event) {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Unknown event type: " + event + ". Supported: " + strings.Join(webhooks.Events, ", "),
+			})
+			return false
+		}
+	}
+	return true
+}
+
+func toWebhookResponse(hook models.Webhook) dtos.WebhookResponse {
+	return dtos.WebhookResponse{
+		ID:        hook.ID,
+		URL:       hook.URL,
+		Events:    strings.Split(hook.Events, ","),
+		Active:    hook.Active,
+		CreatedAt: hook.CreatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(delivery models.WebhookDelivery) dtos.WebhookDeliveryResponse {
+	response := dtos.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		Payload:        delivery.Payload,
+	}
+
+	if delivery.Status == models.DeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		response.NextAttemptAt = &nextAttemptAt
+	}
+
+	return response
+}
@@ -0,0 +1,115 @@
+package dtos
+
+import "time"
+
+// @title AdminUserResponse Struct
+// @notice A user account as seen by admins.
+type AdminUserResponse struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+
+	// @notice When the account was disabled, null while it is active.
+	DisabledAt *time.Time `json:"disabledAt"`
+
+	// @notice Number of live links the user owns.
+	Links int64 `json:"links"`
+
+	// @notice Failed logins counting towards a lockout. Only returned when fetching a single user.
+	FailedLogins int `json:"failedLogins,omitempty"`
+
+	// @notice Login is refused from at least one IP until this time. Only returned when fetching a single user who is locked out.
+	LoginLockedUntil *time.Time `json:"loginLockedUntil,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AdminUserListResponse struct {
+	Users    []AdminUserResponse `json:"users"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"pageSize"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=USER ADMIN"`
+}
+
+// @title AdminLinkResponse Struct
+// @notice Any user's link as seen by admins, including links in the trash.
+type AdminLinkResponse struct {
+	// @notice The link's ID, used by the admin link endpoints since short codes are only unique per domain.
+	ID uint `json:"id"`
+
+	LinkResponse
+
+	OwnerEmail string `json:"ownerEmail"`
+
+	// @notice When the owner deleted the link, null unless it is in the trash.
+	DeletedAt *time.Time `json:"deletedAt"`
+}
+
+type AdminLinkListResponse struct {
+	Links    []AdminLinkResponse `json:"links"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"pageSize"`
+}
+
+// @title SystemStatsResponse Struct
+// @notice System-wide usage figures for admins.
+type SystemStatsResponse struct {
+	Users         int64 `json:"users"`
+	AdminUsers    int64 `json:"adminUsers"`
+	DisabledUsers int64 `json:"disabledUsers"`
+	NewUsers7d    int64 `json:"newUsers7d"`
+
+	// @notice Live links; links in the trash are counted separately.
+	Links        int64 `json:"links"`
+	TrashedLinks int64 `json:"trashedLinks"`
+	NewLinks7d   int64 `json:"newLinks7d"`
+	BrokenLinks  int64 `json:"brokenLinks"`
+
+	// @notice Lifetime click totals across live links.
+	Clicks       int64 `json:"clicks"`
+	UniqueClicks int64 `json:"uniqueClicks"`
+	BotClicks    int64 `json:"botClicks"`
+
+	// @notice Clicks over the last 7 and 30 UTC days, including today.
+	Clicks7d  int64 `json:"clicks7d"`
+	Clicks30d int64 `json:"clicks30d"`
+
+	Domains  int64 `json:"domains"`
+	Webhooks int64 `json:"webhooks"`
+	APIKeys  int64 `json:"apiKeys"`
+}
+
+type LoginLockoutResponse struct {
+	// @notice "account:<email>" or "ip:<address>"; pass it to DELETE /admin/login-lockouts to unlock.
+	Key string `json:"key"`
+
+	Failures int `json:"failures"`
+
+	LastFailureAt time.Time `json:"lastFailureAt"`
+
+	LockedUntil *time.Time `json:"lockedUntil"`
+}
+
+type AuditEventResponse struct {
+	ID        uint      `json:"id"`
+	Type      string    `json:"type"`
+	UserID    *uint     `json:"userId"`
+	Email     string    `json:"email"`
+	IP        string    `json:"ip"`
+	ActorID   *uint     `json:"actorId"`
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AuditEventListResponse struct {
+	Events   []AuditEventResponse `json:"events"`
+	Total    int64                `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"pageSize"`
+}
@@ -0,0 +1,36 @@
+package dtos
+
+import "time"
+
+type DomainRequest struct {
+	// @notice The hostname to register, e.g. go.acme.com. No scheme, path or port.
+	Hostname string `json:"hostname" binding:"required,fqdn,max=253"`
+}
+
+type DomainResponse struct {
+	ID uint `json:"id"`
+
+	Hostname string `json:"hostname"`
+
+	// @notice Whether ownership has been verified. Links can only be created on verified domains.
+	Verified bool `json:"verified"`
+
+	VerifiedAt *time.Time `json:"verifiedAt"`
+
+	// @notice How to prove ownership. Only returned while the domain is unverified.
+	Verification *DomainVerificationInstructions `json:"verification,omitempty"`
+}
+
+type DomainVerificationInstructions struct {
+	// @notice Name of the DNS TXT record to create.
+	TXTRecordName string `json:"txtRecordName"`
+
+	// @notice Value of the DNS TXT record.
+	TXTRecordValue string `json:"txtRecordValue"`
+
+	// @notice Alternatively, serve the token as plain text at this URL.
+	HTTPURL string `json:"httpUrl"`
+
+	// @notice The token to serve at HTTPURL.
+	Token string `json:"token"`
+}
@@ -1,5 +1,7 @@
 package dtos
 
+import "time"
+
 type CreateLinkRequest struct {
 	// @notice The desired custom short code. Must be alphanumeric with hyphens and underscores.
 	// Validation allows: a-z, A-Z, 0-9, hyphens (-), and underscores (_).
@@ -9,6 +11,24 @@ type CreateLinkRequest struct {
 	OriginalURL string `json:"originalUrl" binding:"required,url"`
 
 	UserID uint `json:"userId" binding:"omitempty,min=1"`
+
+	// @notice A verified custom domain to create the link on. Omit for the default domain.
+	Domain string `json:"domain" binding:"omitempty,fqdn"`
+
+	// @notice Display title. Fetched from the destination page when left blank.
+	Title string `json:"title" binding:"omitempty,max=200"`
+
+	// @notice Optional longer description.
+	Description string `json:"description" binding:"omitempty,max=1000"`
+
+	// @notice Private notes for the owner.
+	Notes string `json:"notes" binding:"omitempty,max=5000"`
+
+	// @notice Tag names to attach. Tags that do not exist yet are created.
+	Tags []string `json:"tags" binding:"omitempty,max=20,dive,min=1,max=50"`
+
+	// @notice The folder to file the link under (optional).
+	FolderID *uint `json:"folderId" binding:"omitempty,min=1"`
 }
 
 type LinkUpdateRequest struct {
@@ -17,20 +37,228 @@ type LinkUpdateRequest struct {
 
 	// @notice Flag to activate/deactivate the link (example field).
 	IsActive *bool `json:"isActive" binding:"omitempty"`
+
+	// @notice The new title (optional). Send an empty string to fetch it from the destination page again.
+	Title *string `json:"title" binding:"omitempty,max=200"`
+
+	// @notice The new description (optional).
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+
+	// @notice The new private notes (optional).
+	Notes *string `json:"notes" binding:"omitempty,max=5000"`
+
+	// @notice Replaces the link's tags when present. Send an empty list to remove all tags.
+	Tags *[]string `json:"tags" binding:"omitempty,max=20,dive,min=1,max=50"`
+
+	// @notice Moves the link to another folder when present. Use 0 to unfile the link.
+	FolderID *uint `json:"folderId"`
 }
 type LinkResponse struct {
 	// @notice The unique short identifier.
 	ShortCode string `json:"shortCode"`
 
+	// @notice The custom domain the link lives on, empty for the default domain.
+	Domain string `json:"domain,omitempty"`
+
+	// @notice The complete short link, including the right domain.
+	ShortURL string `json:"shortUrl"`
+
+	// @notice Public page showing where the link goes without following it.
+	PreviewURL string `json:"previewUrl"`
+
+	// @notice PNG image of a QR code that opens the short link.
+	QRURL string `json:"qrUrl"`
+
+	// @notice API endpoint with the link's statistics (owner only).
+	StatsURL string `json:"statsUrl"`
+
 	// @notice The full target URL.
 	OriginalURL string `json:"originalUrl"`
 
 	// @notice The number of times the link has been clicked.
 	Clicks int `json:"clicks"`
 
+	// @notice Distinct visitors, each counted at most once per day.
+	UniqueClicks int `json:"uniqueClicks"`
+
 	// @notice The URL of the favicon, can be null.
 	Favicon *string `json:"favicon"`
 
 	// @notice The User ID this link belongs to.
 	UserID uint `json:"userId"`
+
+	// @notice The display title of the link.
+	Title string `json:"title"`
+
+	// @notice The description of the link.
+	Description string `json:"description"`
+
+	// @notice Private notes for the owner.
+	Notes string `json:"notes"`
+
+	// @notice Additional short codes that redirect to this link.
+	Aliases []string `json:"aliases"`
+
+	// @notice The names of the tags attached to the link.
+	Tags []string `json:"tags"`
+
+	// @notice Result of the last destination health check.
+	Health LinkHealthResponse `json:"health"`
+
+	// @notice The folder the link is filed under, can be null.
+	FolderID *uint `json:"folderId"`
+}
+
+type LinkRevisionResponse struct {
+	// @notice The revision identifier, used when reverting.
+	ID uint `json:"id"`
+
+	// @notice The destination the link pointed to before the change.
+	OriginalURL string `json:"originalUrl"`
+
+	// @notice The User ID of whoever replaced this destination.
+	ChangedByID uint `json:"changedById"`
+
+	// @notice When the destination was replaced.
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+type TrashedLinkResponse struct {
+	LinkResponse
+
+	// @notice When the link was moved to the trash.
+	DeletedAt time.Time `json:"deletedAt"`
+
+	// @notice When the link will be permanently deleted and its short code released.
+	PurgeAt time.Time `json:"purgeAt"`
+}
+
+type LinkAliasRequest struct {
+	// @notice The alias short code. Same rules as a custom short code.
+	ShortCode string `json:"shortCode" binding:"required,min=4,max=20"`
+}
+
+type LinkStatsResponse struct {
+	ShortCode string `json:"shortCode"`
+
+	ShortURL string `json:"shortUrl"`
+
+	// @notice Total number of redirects.
+	Clicks int `json:"clicks"`
+
+	// @notice Distinct visitors, each counted at most once per day. Estimated for very busy links.
+	UniqueClicks int `json:"uniqueClicks"`
+
+	// @notice Redirects made by bots, crawlers and monitors. Not included in clicks.
+	BotClicks int `json:"botClicks"`
+
+	// @notice Bot redirects by bot, busiest first.
+	Bots []LinkBotStats `json:"bots"`
+
+	// @notice Clicks per day over the last 30 days, oldest first.
+	Timeline []LinkClickDayStats `json:"timeline"`
+
+	TopReferrers []LinkReferrerStats `json:"topReferrers"`
+
+	TopCountries []LinkCountryStats `json:"topCountries"`
+
+	// @notice Public stats page, only set while the stats are shared.
+	ShareURL string `json:"shareUrl,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type LinkBotStats struct {
+	// @notice The bot, e.g. Slack or Google.
+	Name string `json:"name"`
+
+	// @notice unfurler, crawler, monitor, tool or other.
+	Category string `json:"category"`
+
+	Hits int `json:"hits"`
+
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+type LinkClickDayStats struct {
+	// @notice The UTC day, formatted as 2006-01-02.
+	Day string `json:"day"`
+
+	Clicks int `json:"clicks"`
+}
+
+type LinkReferrerStats struct {
+	// @notice The referring hostname, empty for direct visits.
+	Referrer string `json:"referrer"`
+
+	Clicks int `json:"clicks"`
+}
+
+type LinkCountryStats struct {
+	// @notice ISO 3166-1 alpha-2 country code, empty when unknown.
+	Country string `json:"country"`
+
+	Clicks int `json:"clicks"`
+}
+
+// @notice Read-only statistics shown on a link's public stats page.
+// Leaves out the owner's private details such as notes and bot traffic.
+type PublicLinkStatsResponse struct {
+	ShortURL string `json:"shortUrl"`
+
+	Title string `json:"title"`
+
+	Clicks int `json:"clicks"`
+
+	UniqueClicks int `json:"uniqueClicks"`
+
+	// @notice Clicks per day over the last 30 days, oldest first.
+	Timeline []LinkClickDayStats `json:"timeline"`
+
+	TopReferrers []LinkReferrerStats `json:"topReferrers"`
+
+	TopCountries []LinkCountryStats `json:"topCountries"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type LinkStatsShareResponse struct {
+	// @notice Whether the stats can be viewed without an account.
+	Public bool `json:"public"`
+
+	// @notice The public stats page, empty while the stats are private.
+	ShareURL string `json:"shareUrl,omitempty"`
+}
+
+type LinkPreviewResponse struct {
+	ShortURL string `json:"shortUrl"`
+
+	// @notice Where the short link redirects to.
+	OriginalURL string `json:"originalUrl"`
+
+	Title string `json:"title"`
+
+	Description string `json:"description"`
+}
+
+type ResolveLinkResponse struct {
+	// @notice Where the short link redirects to.
+	OriginalURL string `json:"originalUrl"`
+}
+
+type LinkHealthResponse struct {
+	// @notice unknown (not checked yet), ok or broken.
+	Status string `json:"status"`
+
+	// @notice HTTP status returned by the destination, 0 if the request failed.
+	StatusCode int `json:"statusCode"`
+
+	// @notice Response time in milliseconds.
+	LatencyMs int64 `json:"latencyMs"`
+
+	// @notice Why the destination is considered broken, if it is.
+	Error string `json:"error,omitempty"`
+
+	// @notice When the destination was last checked, can be null.
+	CheckedAt *time.Time `json:"checkedAt"`
 }
@@ -0,0 +1,15 @@
+package dtos
+
+import "time"
+
+type ReportSettingsRequest struct {
+	// @notice How often to email a link performance digest: never, daily or weekly.
+	Frequency string `json:"frequency" binding:"required,oneof=never daily weekly"`
+}
+
+type ReportSettingsResponse struct {
+	Frequency string `json:"frequency"`
+
+	// @notice When the last digest was sent, null if never.
+	LastSentAt *time.Time `json:"lastSentAt"`
+}
@@ -0,0 +1,44 @@
+package dtos
+
+type TagRequest struct {
+	// @notice The tag name. Tag names are unique per user.
+	Name string `json:"name" binding:"required,min=1,max=50"`
+}
+
+type MergeTagRequest struct {
+	// @notice The tag that will absorb the source tag's links.
+	TargetTagID uint `json:"targetTagId" binding:"required,min=1"`
+}
+
+type TagResponse struct {
+	ID uint `json:"id"`
+
+	Name string `json:"name"`
+
+	// @notice The number of links carrying this tag.
+	LinkCount int64 `json:"linkCount"`
+}
+
+type FolderRequest struct {
+	// @notice The folder name.
+	Name string `json:"name" binding:"required,min=1,max=100"`
+
+	// @notice The enclosing folder. Omit for a top-level folder.
+	ParentID *uint `json:"parentId" binding:"omitempty,min=1"`
+}
+
+type FolderUpdateRequest struct {
+	// @notice The new folder name (optional).
+	Name string `json:"name" binding:"omitempty,min=1,max=100"`
+
+	// @notice The new enclosing folder (optional). Use 0 to move the folder to the top level.
+	ParentID *uint `json:"parentId"`
+}
+
+type FolderResponse struct {
+	ID uint `json:"id"`
+
+	Name string `json:"name"`
+
+	ParentID *uint `json:"parentId"`
+}
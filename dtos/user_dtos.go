@@ -1,6 +1,6 @@
 package dtos
 
-
+import "time"
 
 type CreateUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
@@ -11,11 +11,171 @@ type CreateUserRequest struct {
 type LoginUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6,max=100"`
+
+	// @notice Optional scopes to restrict the session to. Omitted means every scope the user may hold.
+	Scopes []string `json:"scopes"`
 }
 
 type LoginResponse struct {
 	ID    uint   `json:"id"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
+
+	// @notice Whether the user confirmed their email address through the link sent at signup.
+	EmailVerified bool `json:"emailVerified"`
+
+	// @notice Short-lived access token, sent as "Authorization: Bearer <token>".
 	Token string `json:"token"`
+
+	// @notice Always "Bearer".
+	TokenType string `json:"tokenType"`
+
+	// @notice When the access token expires, and the same as seconds from now.
+	ExpiresAt time.Time `json:"expiresAt"`
+	ExpiresIn int64     `json:"expiresIn"`
+
+	// @notice Exchanged at POST /users/refresh for a new access token. Single use.
+	RefreshToken string `json:"refreshToken"`
+
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+
+	// @notice Scopes granted to the access token; kept when it is refreshed.
+	Scopes []string `json:"scopes"`
+}
+
+// @notice Returned by login instead of tokens when the account has two-factor authentication enabled.
+type TwoFactorChallengeResponse struct {
+	// @notice Always true; lets clients tell this apart from LoginResponse.
+	TwoFactorRequired bool `json:"twoFactorRequired"`
+
+	// @notice Sent to POST /users/login/2fa with a code to finish logging in.
+	ChallengeToken string `json:"challengeToken"`
+
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+
+	// @notice A code from the authenticator app, or one of the recovery codes.
+	Code string `json:"code" binding:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required,min=6,max=100"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=6,max=100"`
+}
+
+type ProfileResponse struct {
+	ID               uint      `json:"id"`
+	Name             string    `json:"name"`
+	Email            string    `json:"email"`
+	Role             string    `json:"role"`
+	EmailVerified    bool      `json:"emailVerified"`
+	TwoFactorEnabled bool      `json:"twoFactorEnabled"`
+	ReportFrequency  string    `json:"reportFrequency"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+type UpdateProfileRequest struct {
+	Name  *string `json:"name" binding:"omitempty,min=3,max=100"`
+	Email *string `json:"email" binding:"omitempty,email"`
+
+	// @notice Required when changing the email address.
+	CurrentPassword string `json:"currentPassword"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled bool `json:"enabled"`
+
+	EnabledAt *time.Time `json:"enabledAt"`
+
+	// @notice Recovery codes not used yet.
+	RecoveryCodesRemaining int64 `json:"recoveryCodesRemaining"`
+}
+
+type TwoFactorEnrollResponse struct {
+	// @notice Base32 secret, for entering into the authenticator app by hand.
+	Secret string `json:"secret"`
+
+	// @notice otpauth:// URI for the authenticator app.
+	OTPAuthURI string `json:"otpauthUri"`
+
+	// @notice The otpauth URI as a QR code PNG data URI, ready for an <img> tag.
+	QRCode string `json:"qrCode"`
+}
+
+type EnrollTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type ConfirmTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+
+	// @notice A code from the authenticator app.
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+
+	// @notice A code from the authenticator app, or one of the recovery codes.
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	// @notice Single-use codes that replace an authenticator code. Only shown once.
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type CreateAPIKeyRequest struct {
+	// @notice A label to tell keys apart, e.g. "GitHub Actions".
+	Name string `json:"name" binding:"required,min=1,max=100"`
+
+	// @notice Scopes to grant: links:read, links:write, analytics:read, domains:manage, webhooks:manage, account:manage, admin.
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,required"`
+
+	// @notice When the key stops working (optional, must be in the future).
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type APIKeyResponse struct {
+	ID uint `json:"id"`
+
+	Name string `json:"name"`
+
+	// @notice The first characters of the key, to recognise it.
+	Prefix string `json:"prefix"`
+
+	Scopes []string `json:"scopes"`
+
+	ExpiresAt *time.Time `json:"expiresAt"`
+
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+
+	CreatedAt time.Time `json:"createdAt"`
+
+	// @notice The full key. Only returned when the key is created.
+	Key string `json:"key,omitempty"`
 }
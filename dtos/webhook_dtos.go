@@ -0,0 +1,65 @@
+package dtos
+
+import "time"
+
+type CreateWebhookRequest struct {
+	// @notice The endpoint payloads are POSTed to.
+	URL string `json:"url" binding:"required,url,max=2000"`
+
+	// @notice Event types to subscribe to: link.created, link.updated, link.deleted, link.expired, link.clicked.
+	Events []string `json:"events" binding:"required,min=1,dive,required"`
+
+	// @notice Signing secret. A random secret is generated when omitted.
+	Secret string `json:"secret" binding:"omitempty,min=16,max=200"`
+}
+
+type UpdateWebhookRequest struct {
+	// @notice The new endpoint (optional).
+	URL string `json:"url" binding:"omitempty,url,max=2000"`
+
+	// @notice Replaces the subscribed event types when present.
+	Events []string `json:"events" binding:"omitempty,min=1,dive,required"`
+
+	// @notice Pause or resume deliveries (optional).
+	Active *bool `json:"active"`
+}
+
+type WebhookResponse struct {
+	ID uint `json:"id"`
+
+	URL string `json:"url"`
+
+	Events []string `json:"events"`
+
+	Active bool `json:"active"`
+
+	// @notice The signing secret. Only returned when the webhook is created.
+	Secret string `json:"secret,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID uint `json:"id"`
+
+	Event string `json:"event"`
+
+	// @notice pending, succeeded or failed.
+	Status string `json:"status"`
+
+	Attempts int `json:"attempts"`
+
+	LastStatusCode int `json:"lastStatusCode"`
+
+	LastError string `json:"lastError,omitempty"`
+
+	// @notice When the next attempt is due, for pending deliveries.
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+
+	DeliveredAt *time.Time `json:"deliveredAt"`
+
+	CreatedAt time.Time `json:"createdAt"`
+
+	// @notice The exact JSON body that was signed and sent.
+	Payload string `json:"payload"`
+}
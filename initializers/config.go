@@ -0,0 +1,245 @@
+package initializers
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TrashRetention returns how long soft-deleted links are kept before they are purged.
+// Configured in days via TRASH_RETENTION_DAYS (default 30).
+func TrashRetention() time.Duration {
+	return time.Duration(getEnvInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour
+}
+
+// PublicBaseURLs returns the scheme and host combinations short links on the default domain
+// are served from. The first entry is used when the request does not match any of them.
+// Configured via PUBLIC_BASE_URLS (comma separated) or PUBLIC_BASE_URL (default http://localhost:8080).
+func PublicBaseURLs() []string {
+	baseURLs := splitEnvList(getEnv("PUBLIC_BASE_URLS", getEnv("PUBLIC_BASE_URL", "http://localhost:8080")))
+	for i, baseURL := range baseURLs {
+		baseURLs[i] = strings.TrimRight(baseURL, "/")
+	}
+	if len(baseURLs) == 0 {
+		return []string{"http://localhost:8080"}
+	}
+	return baseURLs
+}
+
+// TrustedProxies returns the IPs/CIDRs of reverse proxies whose X-Forwarded-* headers are honoured.
+// Configured via TRUSTED_PROXIES (comma separated, default none).
+func TrustedProxies() []string {
+	return splitEnvList(getEnv("TRUSTED_PROXIES", ""))
+}
+
+// HealthCheckConfig controls the background link health checker.
+type HealthCheckConfig struct {
+	// Interval between checking rounds (HEALTH_CHECK_INTERVAL_MINUTES, default 60).
+	Interval time.Duration
+	// BatchSize is how many links are checked per round, least recently checked first (HEALTH_CHECK_BATCH_SIZE, default 500).
+	BatchSize int
+	// Concurrency is the maximum number of requests in flight (HEALTH_CHECK_CONCURRENCY, default 10).
+	Concurrency int
+	// HostInterval is the minimum delay between two requests to the same host (HEALTH_CHECK_HOST_INTERVAL_MS, default 1000).
+	HostInterval time.Duration
+	// Timeout bounds each check; slower destinations are flagged as broken (HEALTH_CHECK_TIMEOUT_SECONDS, default 10).
+	Timeout time.Duration
+}
+
+// HealthCheck returns the link health checker configuration.
+func HealthCheck() HealthCheckConfig {
+	return HealthCheckConfig{
+		Interval:     time.Duration(getEnvInt("HEALTH_CHECK_INTERVAL_MINUTES", 60)) * time.Minute,
+		BatchSize:    getEnvInt("HEALTH_CHECK_BATCH_SIZE", 500),
+		Concurrency:  getEnvInt("HEALTH_CHECK_CONCURRENCY", 10),
+		HostInterval: time.Duration(getEnvInt("HEALTH_CHECK_HOST_INTERVAL_MS", 1000)) * time.Millisecond,
+		Timeout:      time.Duration(getEnvInt("HEALTH_CHECK_TIMEOUT_SECONDS", 10)) * time.Second,
+	}
+}
+
+// WebhookMaxAttempts returns how many times a webhook delivery is tried before it is marked failed.
+// Configured via WEBHOOK_MAX_ATTEMPTS (default 8).
+func WebhookMaxAttempts() int {
+	return getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8)
+}
+
+// UniqueVisitorExactLimit returns how many distinct visitors a link can have in one day before
+// they are counted with a HyperLogLog estimate instead of one row per visitor.
+// Configured via UNIQUE_VISITOR_EXACT_LIMIT (default 10000).
+func UniqueVisitorExactLimit() int {
+	return getEnvInt("UNIQUE_VISITOR_EXACT_LIMIT", 10000)
+}
+
+// BotRulesFile returns the path of a user-agent rules file replacing the bundled bot rules.
+// Configured via BOT_RULES_FILE (default none).
+func BotRulesFile() string {
+	return getEnv("BOT_RULES_FILE", "")
+}
+
+// CountryHeader returns the request header a trusted proxy or CDN puts the visitor's country code in.
+// Configured via COUNTRY_HEADER (default CF-IPCountry, as set by Cloudflare).
+func CountryHeader() string {
+	return getEnv("COUNTRY_HEADER", "CF-IPCountry")
+}
+
+// MailConfig holds the SMTP settings used to send email.
+type MailConfig struct {
+	// Host of the SMTP server (SMTP_HOST). Email is kept in memory instead of sent when empty.
+	Host string
+	// Port of the SMTP server (SMTP_PORT, default 587).
+	Port int
+	// Username and Password authenticate with the server when set (SMTP_USERNAME, SMTP_PASSWORD).
+	Username string
+	Password string
+	// From is the sender address (MAIL_FROM, default Shurl <no-reply@localhost>).
+	From string
+}
+
+// Mail returns the SMTP configuration.
+func Mail() MailConfig {
+	return MailConfig{
+		Host:     getEnv("SMTP_HOST", ""),
+		Port:     getEnvInt("SMTP_PORT", 587),
+		Username: getEnv("SMTP_USERNAME", ""),
+		Password: getEnv("SMTP_PASSWORD", ""),
+		From:     getEnv("MAIL_FROM", "Shurl <no-reply@localhost>"),
+	}
+}
+
+// AccessTokenTTL returns how long an access token (JWT) is valid.
+// Configured in minutes via ACCESS_TOKEN_TTL_MINUTES (default 15).
+func AccessTokenTTL() time.Duration {
+	return time.Duration(getEnvInt("ACCESS_TOKEN_TTL_MINUTES", 15)) * time.Minute
+}
+
+// RefreshTokenTTL returns how long a refresh token can be used to get a new access token.
+// Configured in days via REFRESH_TOKEN_TTL_DAYS (default 30).
+func RefreshTokenTTL() time.Duration {
+	return time.Duration(getEnvInt("REFRESH_TOKEN_TTL_DAYS", 30)) * 24 * time.Hour
+}
+
+// EmailVerificationRequired reports whether users must verify their email address before creating links.
+// Configured via REQUIRE_EMAIL_VERIFICATION (default false).
+func EmailVerificationRequired() bool {
+	return getEnvBool("REQUIRE_EMAIL_VERIFICATION", false)
+}
+
+// EmailVerificationTTL returns how long an email verification link stays valid.
+// Configured in hours via EMAIL_VERIFICATION_TTL_HOURS (default 48).
+func EmailVerificationTTL() time.Duration {
+	return time.Duration(getEnvInt("EMAIL_VERIFICATION_TTL_HOURS", 48)) * time.Hour
+}
+
+// PasswordResetTTL returns how long a password reset token can be used.
+// Configured in minutes via PASSWORD_RESET_TTL_MINUTES (default 60).
+func PasswordResetTTL() time.Duration {
+	return time.Duration(getEnvInt("PASSWORD_RESET_TTL_MINUTES", 60)) * time.Minute
+}
+
+// PasswordResetURL returns the page of a frontend that asks for the new password. The reset token
+// is appended as the token query parameter. Configured via PASSWORD_RESET_URL (default none,
+// in which case the email tells the user how to call the API).
+func PasswordResetURL() string {
+	return getEnv("PASSWORD_RESET_URL", "")
+}
+
+// TOTPIssuer returns the name authenticator apps show next to the account for two-factor codes.
+// Configured via TOTP_ISSUER (default Shurl).
+func TOTPIssuer() string {
+	return getEnv("TOTP_ISSUER", "Shurl")
+}
+
+// Where failed login attempts are counted
+const (
+	// LoginGuardDatabase shares counters between instances through the database.
+	LoginGuardDatabase = "database"
+	// LoginGuardMemory keeps counters in the process; only suitable for a single instance.
+	LoginGuardMemory = "memory"
+)
+
+// LoginGuardConfig controls brute-force protection for login.
+type LoginGuardConfig struct {
+	// Store is where failed attempts are counted: database (default) or memory (LOGIN_GUARD_STORE).
+	Store string
+	// MaxFailures is how many failed logins from one IP lock an account for that IP (LOGIN_MAX_FAILURES,
+	// default 10). Failures from everywhere only delay attempts, so nobody can lock an account for its owner.
+	MaxFailures int
+	// IPMaxFailures is how many failed logins, across all accounts, lock an IP (LOGIN_IP_MAX_FAILURES, default 50).
+	IPMaxFailures int
+	// Lockout is the first lockout; each further failure while over the limit doubles it, up to a day
+	// (LOGIN_LOCKOUT_MINUTES, default 15).
+	Lockout time.Duration
+	// Window is how long after the last failure the counters are forgotten (LOGIN_FAILURE_WINDOW_MINUTES, default 60).
+	Window time.Duration
+}
+
+// LoginGuard returns the login brute-force protection configuration.
+func LoginGuard() LoginGuardConfig {
+	store := getEnv("LOGIN_GUARD_STORE", LoginGuardDatabase)
+	if store != LoginGuardDatabase && store != LoginGuardMemory {
+		log.Printf("Invalid value for LOGIN_GUARD_STORE, using default %s", LoginGuardDatabase)
+		store = LoginGuardDatabase
+	}
+
+	return LoginGuardConfig{
+		Store:         store,
+		MaxFailures:   getEnvInt("LOGIN_MAX_FAILURES", 10),
+		IPMaxFailures: getEnvInt("LOGIN_IP_MAX_FAILURES", 50),
+		Lockout:       time.Duration(getEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		Window:        time.Duration(getEnvInt("LOGIN_FAILURE_WINDOW_MINUTES", 60)) * time.Minute,
+	}
+}
+
+// AuditRetention returns how long audit events are kept.
+// Configured in days via AUDIT_RETENTION_DAYS (default 90).
+func AuditRetention() time.Duration {
+	return time.Duration(getEnvInt("AUDIT_RETENTION_DAYS", 90)) * 24 * time.Hour
+}
+
+// What happens to a user's links when they delete their account
+const (
+	// AccountDeletionDelete permanently deletes the links with the account.
+	AccountDeletionDelete = "delete"
+	// AccountDeletionAnonymize keeps the links working but detaches them from the account.
+	AccountDeletionAnonymize = "anonymize"
+)
+
+// AccountDeletionPolicy returns what happens to a user's links when they delete their account.
+// Configured via ACCOUNT_DELETION_POLICY: delete (default) or anonymize.
+func AccountDeletionPolicy() string {
+	policy := getEnv("ACCOUNT_DELETION_POLICY", AccountDeletionDelete)
+	if policy != AccountDeletionDelete && policy != AccountDeletionAnonymize {
+		log.Printf("Invalid value for ACCOUNT_DELETION_POLICY, using default %s", AccountDeletionDelete)
+		return AccountDeletionDelete
+	}
+	return policy
+}
+
+func splitEnvList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, strconv.Itoa(defaultValue)))
+	if err != nil {
+		log.Printf("Invalid value for %s, using default %d", key, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(getEnv(key, strconv.FormatBool(defaultValue)))
+	if err != nil {
+		log.Printf("Invalid value for %s, using default %t", key, defaultValue)
+		return defaultValue
+	}
+	return value
+}
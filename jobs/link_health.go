@@ -0,0 +1,266 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/utils"
+)
+
+// HealthResult is the outcome of checking one destination URL.
+type HealthResult struct {
+	Status     string
+	StatusCode int
+	Latency    time.Duration
+	Error      string
+	CheckedAt  time.Time
+}
+
+// HealthChecker issues HEAD (falling back to GET) requests against link destinations.
+// It caps the number of requests in flight and spaces out requests to the same host,
+// so a user with thousands of links to one site does not hammer it.
+type HealthChecker struct {
+	Client       *http.Client
+	Concurrency  int
+	HostInterval time.Duration
+
+	mu           sync.Mutex
+	nextHostSlot map[string]time.Time
+}
+
+// NewHealthChecker builds a checker from the given configuration. Destinations are user
+// supplied, so its client refuses to connect to private and loopback addresses.
+func NewHealthChecker(config initializers.HealthCheckConfig) *HealthChecker {
+	return &HealthChecker{
+		Client:       utils.NewPublicHTTPClient(config.Timeout),
+		Concurrency:  config.Concurrency,
+		HostInterval: config.HostInterval,
+	}
+}
+
+// StartLinkHealthChecker launches a background goroutine that periodically checks the
+// least recently checked links and records the results.
+func StartLinkHealthChecker() {
+	config := initializers.HealthCheck()
+	checker := NewHealthChecker(config)
+
+	go func() {
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+
+		for {
+			CheckLinkHealth(context.Background(), checker, config.BatchSize)
+			<-ticker.C
+		}
+	}()
+}
+
+// CheckLinkHealth checks up to batchSize links, oldest check first, and saves the results.
+func CheckLinkHealth(ctx context.Context, checker *HealthChecker, batchSize int) {
+	var links []models.Link
+	err := initializers.DB.Select("id", "original_url").
+		Order("health_checked_at ASC NULLS FIRST").
+		Limit(batchSize).
+		Find(&links).Error
+	if err != nil {
+		log.Println("Failed to load links for health check:", err)
+		return
+	}
+
+	urls := make([]string, len(links))
+	for i, link := range links {
+		urls[i] = link.OriginalURL
+	}
+
+	results := checker.CheckAll(ctx, urls)
+
+	broken := 0
+	for i, result := range results {
+		if result.Status == models.HealthBroken {
+			broken++
+		}
+
+		// The owner may have changed the destination while it was being checked
+		err := initializers.DB.Model(&models.Link{}).Where("id = ? AND original_url = ?", links[i].ID, links[i].OriginalURL).Updates(map[string]interface{}{
+			"health_status":      result.Status,
+			"health_status_code": result.StatusCode,
+			"health_latency_ms":  result.Latency.Milliseconds(),
+			"health_error":       result.Error,
+			"health_checked_at":  result.CheckedAt,
+		}).Error
+		if err != nil {
+			log.Printf("Failed to save health check for link %d: %v", links[i].ID, err)
+		}
+	}
+
+	if len(links) > 0 {
+		log.Printf("Checked %d links, %d broken", len(links), broken)
+	}
+}
+
+// CheckAll checks every URL, honouring the concurrency and per-host limits.
+// Results are returned in the same order as urls.
+func (h *HealthChecker) CheckAll(ctx context.Context, urls []string) []HealthResult {
+	results := make([]HealthResult, len(urls))
+
+	concurrency := h.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	slots := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, rawURL := range urls {
+		wg.Add(1)
+
+		go func(i int, rawURL string) {
+			defer wg.Done()
+
+			// Wait for the host before taking a slot, so spacing out one busy host
+			// doesn't hold up requests to every other host
+			if result, ok := h.waitForURL(ctx, rawURL); !ok {
+				results[i] = result
+				return
+			}
+
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = HealthResult{Status: models.HealthBroken, Error: ctx.Err().Error(), CheckedAt: time.Now()}
+				return
+			}
+			defer func() { <-slots }()
+
+			results[i] = h.probe(ctx, rawURL)
+		}(i, rawURL)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Check requests a single URL. 2xx and 3xx responses are healthy; 4xx, 5xx,
+// timeouts and connection errors are broken.
+func (h *HealthChecker) Check(ctx context.Context, rawURL string) HealthResult {
+	if result, ok := h.waitForURL(ctx, rawURL); !ok {
+		return result
+	}
+	return h.probe(ctx, rawURL)
+}
+
+// waitForURL validates the URL and waits for its host's request slot. When it
+// returns false the URL must not be requested and the result says why.
+func (h *HealthChecker) waitForURL(ctx context.Context, rawURL string) (HealthResult, bool) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return HealthResult{Status: models.HealthBroken, Error: "invalid URL", CheckedAt: time.Now()}, false
+	}
+
+	if err := h.waitForHost(ctx, parsed.Host); err != nil {
+		return HealthResult{Status: models.HealthBroken, Error: err.Error(), CheckedAt: time.Now()}, false
+	}
+
+	return HealthResult{}, true
+}
+
+// probe requests the URL with HEAD, falling back to GET, and classifies the response.
+func (h *HealthChecker) probe(ctx context.Context, rawURL string) HealthResult {
+	start := time.Now()
+	statusCode, err := h.request(ctx, http.MethodHead, rawURL)
+
+	// Some servers do not implement HEAD; retry those with GET
+	if err == nil && (statusCode == http.StatusMethodNotAllowed || statusCode == http.StatusNotImplemented) {
+		start = time.Now()
+		statusCode, err = h.request(ctx, http.MethodGet, rawURL)
+	}
+
+	result := HealthResult{
+		StatusCode: statusCode,
+		Latency:    time.Since(start),
+		CheckedAt:  time.Now(),
+	}
+
+	switch {
+	case err != nil:
+		result.Status = models.HealthBroken
+		result.Error = describeHealthError(err)
+	case statusCode >= 400:
+		result.Status = models.HealthBroken
+		result.Error = http.StatusText(statusCode)
+	default:
+		result.Status = models.HealthOK
+	}
+
+	return result
+}
+
+func (h *HealthChecker) request(ctx context.Context, method string, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "Shurl-HealthCheck/1.0 (+https://github.com/olujimiAdebakin/Shurl)")
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// waitForHost blocks until the host's next request slot, reserving the slot after it.
+func (h *HealthChecker) waitForHost(ctx context.Context, host string) error {
+	h.mu.Lock()
+	if h.nextHostSlot == nil {
+		h.nextHostSlot = map[string]time.Time{}
+	}
+
+	now := time.Now()
+	slot := h.nextHostSlot[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	h.nextHostSlot[host] = slot.Add(h.HostInterval)
+	h.mu.Unlock()
+
+	wait := time.Until(slot)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Helper function: Turn transport errors into a short reason for the link owner
+func describeHealthError(err error) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Timeout() {
+		return "timeout"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	return err.Error()
+}
@@ -0,0 +1,198 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+// newTestChecker returns a checker with a plain client, since httptest servers listen on
+// loopback addresses that the production client refuses.
+func newTestChecker(timeout time.Duration) *HealthChecker {
+	return &HealthChecker{
+		Client:      &http.Client{Timeout: timeout},
+		Concurrency: 4,
+	}
+}
+
+func statusServer(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCheckHealthy(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusNoContent, http.StatusMovedPermanently} {
+		server := statusServer(t, status)
+
+		result := newTestChecker(time.Second).Check(context.Background(), server.URL)
+		if result.Status != models.HealthOK {
+			t.Errorf("status %d: got %q (%s), want %q", status, result.Status, result.Error, models.HealthOK)
+		}
+		if result.StatusCode != status {
+			t.Errorf("status %d: recorded status code %d", status, result.StatusCode)
+		}
+		if result.CheckedAt.IsZero() {
+			t.Errorf("status %d: CheckedAt not set", status)
+		}
+	}
+}
+
+func TestCheckFallsBackToGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	result := newTestChecker(time.Second).Check(context.Background(), server.URL)
+	if result.Status != models.HealthOK || result.StatusCode != http.StatusOK {
+		t.Fatalf("got %q with status code %d, want healthy 200", result.Status, result.StatusCode)
+	}
+}
+
+func TestCheckClientAndServerErrors(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusGone, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		server := statusServer(t, status)
+
+		result := newTestChecker(time.Second).Check(context.Background(), server.URL)
+		if result.Status != models.HealthBroken {
+			t.Errorf("status %d: got %q, want %q", status, result.Status, models.HealthBroken)
+		}
+		if result.StatusCode != status {
+			t.Errorf("status %d: recorded status code %d", status, result.StatusCode)
+		}
+		if result.Error != http.StatusText(status) {
+			t.Errorf("status %d: error %q, want %q", status, result.Error, http.StatusText(status))
+		}
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	result := newTestChecker(50*time.Millisecond).Check(context.Background(), server.URL)
+	if result.Status != models.HealthBroken {
+		t.Fatalf("got %q, want %q", result.Status, models.HealthBroken)
+	}
+	if result.Error != "timeout" {
+		t.Fatalf("error %q, want %q", result.Error, "timeout")
+	}
+}
+
+func TestCheckInvalidURL(t *testing.T) {
+	result := newTestChecker(time.Second).Check(context.Background(), "not a url")
+	if result.Status != models.HealthBroken || result.Error != "invalid URL" {
+		t.Fatalf("got %q (%s), want broken invalid URL", result.Status, result.Error)
+	}
+}
+
+func TestNewHealthCheckerRefusesLoopback(t *testing.T) {
+	var requested bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	checker := NewHealthChecker(initializers.HealthCheckConfig{Concurrency: 1, Timeout: time.Second})
+	result := checker.Check(context.Background(), server.URL)
+	if result.Status != models.HealthBroken {
+		t.Fatalf("got %q, want %q", result.Status, models.HealthBroken)
+	}
+	if requested {
+		t.Fatal("loopback destination was requested")
+	}
+}
+
+// requestLog records when each request reached a test server.
+type requestLog struct {
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (l *requestLog) handler(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	l.times = append(l.times, time.Now())
+	l.mu.Unlock()
+}
+
+func TestCheckAllSpacesRequestsPerHost(t *testing.T) {
+	const interval = 80 * time.Millisecond
+
+	var busy, other requestLog
+	busyServer := httptest.NewServer(http.HandlerFunc(busy.handler))
+	defer busyServer.Close()
+	otherServer := httptest.NewServer(http.HandlerFunc(other.handler))
+	defer otherServer.Close()
+
+	checker := newTestChecker(time.Second)
+	checker.Concurrency = 1
+	checker.HostInterval = interval
+
+	urls := []string{busyServer.URL + "/a", busyServer.URL + "/b", busyServer.URL + "/c", otherServer.URL}
+	start := time.Now()
+	results := checker.CheckAll(context.Background(), urls)
+
+	for i, result := range results {
+		if result.Status != models.HealthOK {
+			t.Fatalf("%s: got %q (%s)", urls[i], result.Status, result.Error)
+		}
+	}
+
+	if len(busy.times) != 3 {
+		t.Fatalf("busy host got %d requests, want 3", len(busy.times))
+	}
+	for i := 1; i < len(busy.times); i++ {
+		// Allow a little timer jitter
+		if gap := busy.times[i].Sub(busy.times[i-1]); gap < interval-10*time.Millisecond {
+			t.Errorf("requests %d and %d to the same host were %v apart, want at least %v", i-1, i, gap, interval)
+		}
+	}
+
+	// Waiting for the busy host must not hold the only slot and delay other hosts
+	if len(other.times) != 1 {
+		t.Fatalf("other host got %d requests, want 1", len(other.times))
+	}
+	if waited := other.times[0].Sub(start); waited >= interval {
+		t.Errorf("other host was requested after %v, want it not to wait behind the busy host", waited)
+	}
+}
+
+func TestCheckAllKeepsOrder(t *testing.T) {
+	ok := statusServer(t, http.StatusOK)
+	missing := statusServer(t, http.StatusNotFound)
+
+	urls := []string{ok.URL, missing.URL, "::bad", ok.URL + "/again"}
+	results := newTestChecker(time.Second).CheckAll(context.Background(), urls)
+
+	want := []string{models.HealthOK, models.HealthBroken, models.HealthBroken, models.HealthOK}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("%s: got %q, want %q", urls[i], result.Status, want[i])
+		}
+	}
+	if !strings.Contains(results[2].Error, "invalid") {
+		t.Errorf("bad URL error %q", results[2].Error)
+	}
+}
@@ -0,0 +1,81 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/mailer"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/reports"
+)
+
+// reportDigestInterval is how often users are checked for due digests.
+const reportDigestInterval = time.Hour
+
+// StartReportDigests launches a background goroutine that emails daily and weekly
+// link performance digests to the users who asked for them.
+func StartReportDigests() {
+	go func() {
+		ticker := time.NewTicker(reportDigestInterval)
+		defer ticker.Stop()
+
+		for {
+			SendDueReports(context.Background(), mailer.Default(), time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+// SendDueReports sends every digest whose period has ended since it was last sent.
+// Users who have not verified their email address are skipped until they do.
+func SendDueReports(ctx context.Context, m mailer.Mailer, now time.Time) {
+	var users []models.User
+	err := initializers.DB.
+		Where("report_frequency IN ? AND email_verified_at IS NOT NULL", []string{models.ReportDaily, models.ReportWeekly}).
+		Find(&users).Error
+	if err != nil {
+		log.Println("Failed to load report subscribers:", err)
+		return
+	}
+
+	sent := 0
+	for _, user := range users {
+		if !reports.Due(user, now) {
+			continue
+		}
+
+		// Claim the digest first so only one instance sends it
+		_, periodEnd := reports.Period(user.ReportFrequency, now)
+		claim := initializers.DB.Model(&models.User{}).
+			Where("id = ? AND (report_last_sent_at IS NULL OR report_last_sent_at < ?)", user.ID, periodEnd).
+			UpdateColumn("report_last_sent_at", now)
+		if claim.Error != nil {
+			log.Printf("Failed to claim report for user %d: %v", user.ID, claim.Error)
+			continue
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		err := reports.Send(ctx, m, user, user.ReportFrequency, now)
+		if errors.Is(err, reports.ErrNothingToReport) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to send report to user %d: %v", user.ID, err)
+
+			// Release the claim so the digest is retried on the next run
+			initializers.DB.Model(&models.User{}).Where("id = ?", user.ID).
+				UpdateColumn("report_last_sent_at", user.ReportLastSentAt)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		log.Printf("Sent %d report digests", sent)
+	}
+}
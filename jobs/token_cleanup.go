@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/audit"
+	"github.com/olujimiAdebakin/Shurl/auth"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/loginguard"
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+// tokenCleanupInterval is how often expired refresh tokens, password reset tokens and revocations,
+// stale failed login counters and old audit events are deleted.
+const tokenCleanupInterval = time.Hour
+
+// StartTokenCleanup launches a background goroutine that deletes expired refresh and password
+// reset tokens, the revocations of access tokens that have expired, failed login counters that
+// no longer matter and audit events past their retention.
+func StartTokenCleanup() {
+	go func() {
+		ticker := time.NewTicker(tokenCleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			DeleteExpiredTokens(time.Now())
+			DeleteStaleSecurityRecords(time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+// DeleteExpiredTokens hard-deletes refresh and password reset tokens that can no longer be used
+// and revocations that are no longer needed.
+func DeleteExpiredTokens(now time.Time) {
+	if err := auth.PruneRevokedTokens(now); err != nil {
+		log.Println("Failed to delete expired token revocations:", err)
+	}
+
+	if err := initializers.DB.Where("expires_at < ?", now).Delete(&models.PasswordResetToken{}).Error; err != nil {
+		log.Println("Failed to delete expired password reset tokens:", err)
+	}
+
+	result := initializers.DB.Unscoped().Where("expires_at < ?", now).Delete(&models.RefreshToken{})
+	if result.Error != nil {
+		log.Println("Failed to delete expired refresh tokens:", result.Error)
+		return
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Deleted %d expired refresh tokens", result.RowsAffected)
+	}
+}
+
+// DeleteStaleSecurityRecords deletes failed login counters outside the counting window and
+// audit events older than AUDIT_RETENTION_DAYS.
+func DeleteStaleSecurityRecords(now time.Time) {
+	if err := loginguard.Default().Prune(context.Background(), now); err != nil {
+		log.Println("Failed to delete stale failed login counters:", err)
+	}
+
+	deleted, err := audit.Prune(now.Add(-initializers.AuditRetention()))
+	if err != nil {
+		log.Println("Failed to delete old audit events:", err)
+		return
+	}
+
+	if deleted > 0 {
+		log.Printf("Deleted %d old audit events", deleted)
+	}
+}
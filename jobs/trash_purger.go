@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/webhooks"
+)
+
+// trashPurgeInterval is how often the purger looks for expired links.
+const trashPurgeInterval = time.Hour
+
+// StartTrashPurger launches a background goroutine that permanently deletes links
+// which have been in the trash longer than the configured retention period,
+// releasing their short codes for reuse.
+func StartTrashPurger() {
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			PurgeExpiredLinks()
+			<-ticker.C
+		}
+	}()
+}
+
+// PurgeExpiredLinks hard-deletes every soft-deleted link older than the retention period
+// and notifies the owners' webhooks with a link.expired event.
+func PurgeExpiredLinks() {
+	cutoff := time.Now().Add(-initializers.TrashRetention())
+
+	var links []models.Link
+	err := initializers.DB.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Find(&links).Error
+	if err != nil {
+		log.Println("Failed to load expired links:", err)
+		return
+	}
+
+	for _, link := range links {
+		if err := initializers.DB.Unscoped().Delete(&link).Error; err != nil {
+			log.Printf("Failed to purge link %d: %v", link.ID, err)
+			continue
+		}
+
+		webhooks.Enqueue(link.UserID, webhooks.EventLinkExpired, map[string]interface{}{
+			"shortCode":   link.ShortCode,
+			"originalUrl": link.OriginalURL,
+			"userId":      link.UserID,
+			"deletedAt":   link.DeletedAt.Time,
+		})
+	}
+
+	if len(links) > 0 {
+		log.Printf("Purged %d deleted links", len(links))
+	}
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/analytics"
+)
+
+// visitorPruneInterval is how often expired visitor salts and hashes are deleted.
+const visitorPruneInterval = time.Hour
+
+// StartVisitorPruner launches a background goroutine that deletes the previous days'
+// visitor salts and hashes, so unique visitor counts cannot be traced back to anyone.
+func StartVisitorPruner() {
+	go func() {
+		ticker := time.NewTicker(visitorPruneInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := analytics.PruneVisitorData(time.Now()); err != nil {
+				log.Println("Failed to prune visitor data:", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,191 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+	"github.com/olujimiAdebakin/Shurl/utils"
+	"github.com/olujimiAdebakin/Shurl/webhooks"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// webhookPollInterval is how often the queue is checked for due deliveries.
+	webhookPollInterval = 5 * time.Second
+	// webhookBatchSize is how many deliveries one worker claims per poll.
+	webhookBatchSize = 10
+	// webhookTimeout bounds a single delivery attempt.
+	webhookTimeout = 10 * time.Second
+	// webhookLease keeps claimed deliveries away from other workers while they are being sent.
+	// The batch is sent one by one, so it must outlast a batch of timed out attempts.
+	webhookLease = webhookBatchSize*webhookTimeout + time.Minute
+	// webhookBaseBackoff is the delay before the first retry; it doubles on each failure.
+	webhookBaseBackoff = 30 * time.Second
+	// webhookMaxBackoff caps the delay between retries.
+	webhookMaxBackoff = 6 * time.Hour
+)
+
+// webhookClient refuses to connect to private and loopback addresses, since endpoints are user supplied.
+var webhookClient = utils.NewPublicHTTPClient(webhookTimeout)
+
+// StartWebhookDispatcher launches a background goroutine that sends queued webhook deliveries.
+// Several instances can run at once: deliveries are claimed with SKIP LOCKED and a short lease.
+func StartWebhookDispatcher() {
+	go func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+
+		for {
+			DeliverDueWebhooks(context.Background())
+			<-ticker.C
+		}
+	}()
+}
+
+// DeliverDueWebhooks claims and sends every delivery whose next attempt is due.
+func DeliverDueWebhooks(ctx context.Context) {
+	for {
+		deliveries, err := claimDueDeliveries()
+		if err != nil {
+			log.Println("Failed to claim webhook deliveries:", err)
+			return
+		}
+
+		for _, delivery := range deliveries {
+			sendWebhookDelivery(ctx, delivery)
+		}
+
+		if len(deliveries) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// Helper function: Lock a batch of due deliveries and push their next attempt out by the lease.
+// Pending deliveries of inactive webhooks are marked failed instead of being sent.
+func claimDueDeliveries() ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.WebhookDelivery{}).
+			Where("status = ? AND webhook_id IN (?)", models.DeliveryPending,
+				tx.Model(&models.Webhook{}).Select("id").Where("active = ?", false)).
+			Updates(map[string]interface{}{
+				"status":     models.DeliveryFailed,
+				"last_error": "webhook is inactive",
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, time.Now()).
+			Where("webhook_id IN (?)", tx.Model(&models.Webhook{}).Select("id").Where("active = ?", true)).
+			Order("next_attempt_at").
+			Limit(webhookBatchSize).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(webhookLease)).Error
+	})
+	if err != nil || len(deliveries) == 0 {
+		return deliveries, err
+	}
+
+	// Load the endpoints separately; row locks cannot be combined with the join
+	for i := range deliveries {
+		if err := initializers.DB.First(&deliveries[i].Webhook, deliveries[i].WebhookID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return deliveries, nil
+}
+
+// Helper function: POST one delivery and record the outcome, scheduling a retry on failure
+func sendWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) {
+	body := []byte(delivery.Payload)
+	attempts := delivery.Attempts + 1
+
+	statusCode, err := postWebhook(ctx, delivery, body)
+
+	updates := map[string]interface{}{
+		"attempts":         attempts,
+		"last_status_code": statusCode,
+		"last_error":       "",
+	}
+
+	switch {
+	case err == nil && statusCode >= 200 && statusCode < 300:
+		updates["status"] = models.DeliverySucceeded
+		updates["delivered_at"] = time.Now()
+	default:
+		if err != nil {
+			updates["last_error"] = err.Error()
+		} else {
+			updates["last_error"] = "endpoint responded with " + strconv.Itoa(statusCode)
+		}
+
+		if attempts >= initializers.WebhookMaxAttempts() {
+			updates["status"] = models.DeliveryFailed
+		} else {
+			updates["next_attempt_at"] = time.Now().Add(webhookBackoff(attempts))
+		}
+	}
+
+	if err := initializers.DB.Model(&delivery).Updates(updates).Error; err != nil {
+		log.Printf("Failed to record webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+func postWebhook(ctx context.Context, delivery models.WebhookDelivery, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Shurl-Webhooks/1.0")
+	req.Header.Set(webhooks.EventHeader, delivery.Event)
+	req.Header.Set(webhooks.DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign(delivery.Webhook.Secret, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	return resp.StatusCode, nil
+}
+
+// Helper function: Exponential backoff for the given number of failed attempts
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return backoff
+}
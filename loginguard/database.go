@@ -0,0 +1,121 @@
+package loginguard
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DatabaseStore keeps counters in the login_attempts table, shared by every instance.
+type DatabaseStore struct {
+	db *gorm.DB
+}
+
+// NewDatabaseStore creates a store backed by db.
+func NewDatabaseStore(db *gorm.DB) *DatabaseStore {
+	return &DatabaseStore{db: db}
+}
+
+// Get returns the counter for key.
+func (s *DatabaseStore) Get(ctx context.Context, key string) (Attempts, error) {
+	var row models.LoginAttempt
+	result := s.db.WithContext(ctx).Where("key = ?", key).Limit(1).Find(&row)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return Attempts{Key: key}, result.Error
+	}
+	return toAttempts(row), nil
+}
+
+// RecordFailure adds a failure to the counter for key in a single upsert, so concurrent
+// failures on several instances are all counted.
+func (s *DatabaseStore) RecordFailure(ctx context.Context, key string, now time.Time, windowStart time.Time) (Attempts, error) {
+	row := models.LoginAttempt{
+		Key:           key,
+		Failures:      1,
+		LastFailureAt: now,
+	}
+
+	err := s.db.WithContext(ctx).Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"failures":        gorm.Expr("CASE WHEN GREATEST(login_attempts.last_failure_at, COALESCE(login_attempts.locked_until, login_attempts.last_failure_at)) < ? THEN 1 ELSE login_attempts.failures + 1 END", windowStart),
+				"last_failure_at": now,
+			}),
+		},
+		clause.Returning{},
+	).Create(&row).Error
+	if err != nil {
+		return Attempts{Key: key}, err
+	}
+	return toAttempts(row), nil
+}
+
+// Release takes back one failure from the counter for key.
+func (s *DatabaseStore) Release(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Model(&models.LoginAttempt{}).
+		Where("key = ? AND failures > 0", key).
+		Update("failures", gorm.Expr("failures - 1")).Error
+}
+
+// Lock refuses logins for key until the given time.
+func (s *DatabaseStore) Lock(ctx context.Context, key string, until time.Time) error {
+	return s.db.WithContext(ctx).Model(&models.LoginAttempt{}).Where("key = ?", key).Update("locked_until", until).Error
+}
+
+// Claim locks key until the given time unless it is locked at now. The check and the update are
+// one statement, so only one of several instances claiming at once succeeds.
+func (s *DatabaseStore) Claim(ctx context.Context, key string, now time.Time, until time.Time) (bool, error) {
+	result := s.db.WithContext(ctx).Model(&models.LoginAttempt{}).
+		Where("key = ? AND (locked_until IS NULL OR locked_until <= ?)", key, now).
+		Update("locked_until", until)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Reset forgets the counter for key.
+func (s *DatabaseStore) Reset(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+// ResetPrefix forgets every counter whose key starts with prefix.
+func (s *DatabaseStore) ResetPrefix(ctx context.Context, prefix string) error {
+	pattern := likeEscaper.Replace(prefix) + "%"
+	return s.db.WithContext(ctx).Where("key LIKE ? ESCAPE '\\'", pattern).Delete(&models.LoginAttempt{}).Error
+}
+
+// Locked returns the counters locked at now.
+func (s *DatabaseStore) Locked(ctx context.Context, now time.Time) ([]Attempts, error) {
+	var rows []models.LoginAttempt
+	if err := s.db.WithContext(ctx).Where("locked_until > ?", now).Order("locked_until DESC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	locked := []Attempts{}
+	for _, row := range rows {
+		locked = append(locked, toAttempts(row))
+	}
+	return locked, nil
+}
+
+// Prune deletes counters whose last failure and lockout both ended before the window.
+func (s *DatabaseStore) Prune(ctx context.Context, windowStart time.Time) error {
+	return s.db.WithContext(ctx).
+		Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", windowStart, windowStart).
+		Delete(&models.LoginAttempt{}).Error
+}
+
+// likeEscaper escapes the LIKE wildcards in a literal prefix.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func toAttempts(row models.LoginAttempt) Attempts {
+	return Attempts{
+		Key:           row.Key,
+		Failures:      row.Failures,
+		LastFailureAt: row.LastFailureAt,
+		LockedUntil:   row.LockedUntil,
+	}
+}
@@ -0,0 +1,429 @@
+// Package loginguard protects login against password guessing. Failed attempts are counted per
+// account, per account and client IP, and per client IP. Accounts get progressively longer delays
+// between attempts but are never locked as a whole, so nobody can lock someone else out; an account
+// is only locked for the IP guessing at it, and an IP for all accounts once it reaches its limit.
+package loginguard
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+)
+
+const (
+	// freeFailures is how many failures an account gets before attempts are delayed.
+	freeFailures = 3
+
+	// maxDelay caps the delay between attempts before the account is locked.
+	maxDelay = 30 * time.Second
+
+	// maxLockout caps progressive lockouts.
+	maxLockout = 24 * time.Hour
+)
+
+// Attempts is the failed login counter for one account, account and IP pair, or IP.
+type Attempts struct {
+	// Key is AccountKey, AccountIPKey or IPKey of what is being counted.
+	Key string
+	// Failures since the counter was last reset.
+	Failures int
+	// LastFailureAt is zero when there were no failures.
+	LastFailureAt time.Time
+	// LockedUntil is set while, and after, a lockout.
+	LockedUntil *time.Time
+}
+
+// Store keeps failed login counters. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the counter for key, with no failures when there is none.
+	Get(ctx context.Context, key string) (Attempts, error)
+	// RecordFailure adds a failure to the counter for key and returns the new counter. The count
+	// starts over when the previous failure and lockout both ended before windowStart.
+	RecordFailure(ctx context.Context, key string, now time.Time, windowStart time.Time) (Attempts, error)
+	// Release takes back one failure added by RecordFailure, for an attempt that did not fail.
+	Release(ctx context.Context, key string) error
+	// Lock refuses logins for key until the given time.
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Claim locks key until the given time unless it is already locked at now, and reports
+	// whether it did. Of concurrent calls, only one succeeds.
+	Claim(ctx context.Context, key string, now time.Time, until time.Time) (bool, error)
+	// Reset forgets the counter for key, lifting any lockout.
+	Reset(ctx context.Context, key string) error
+	// ResetPrefix forgets every counter whose key starts with prefix.
+	ResetPrefix(ctx context.Context, prefix string) error
+	// Locked returns the counters with a lockout active at now, longest lockout first.
+	Locked(ctx context.Context, now time.Time) ([]Attempts, error)
+	// Prune deletes counters whose last failure and lockout both ended before windowStart.
+	Prune(ctx context.Context, windowStart time.Time) error
+}
+
+// Policy sets the limits a Guard enforces.
+type Policy struct {
+	// MaxFailures locks an account for the IP the failures come from; 0 disables these lockouts.
+	MaxFailures int
+	// IPMaxFailures locks an IP; 0 disables IP lockouts.
+	IPMaxFailures int
+	// Lockout is the first lockout, doubled for every failure past the limit after it ends.
+	Lockout time.Duration
+	// Window is how long after the last failure, or the end of the last lockout, a counter is forgotten.
+	// Failures after a lockout therefore lead to a longer one.
+	Window time.Duration
+}
+
+// Delay returns how long an account must wait after its last failure before trying again:
+// nothing for the first few failures, then 1s, 2s, 4s... up to 30 seconds.
+func (p Policy) Delay(failures int) time.Duration {
+	if failures < freeFailures {
+		return 0
+	}
+	shift := failures - freeFailures
+	if shift > 5 {
+		return maxDelay
+	}
+	delay := time.Second << uint(shift)
+	if delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
+
+// LockoutFor returns how long to lock after the given number of failures against a limit.
+func (p Policy) LockoutFor(failures int, limit int) time.Duration {
+	over := failures - limit
+	if over > 10 {
+		return maxLockout
+	}
+	lockout := p.Lockout << uint(over)
+	if lockout > maxLockout || lockout <= 0 {
+		return maxLockout
+	}
+	return lockout
+}
+
+// Decision is the answer to whether a login attempt may go ahead.
+type Decision struct {
+	Allowed bool
+	// RetryAt is when the next attempt will be accepted, when not allowed.
+	RetryAt time.Time
+	// Locked is true for lockouts and false for the short delays before them.
+	Locked bool
+
+	// reserved are the keys an allowed attempt was counted against, and claimed the ones it
+	// locked for everyone else because it is the last attempt their limit allows.
+	reserved []string
+	claimed  map[string]time.Time
+}
+
+// Failure describes what a failed attempt led to.
+type Failure struct {
+	// Failures counted against the account so far, from any IP.
+	Failures int
+	// AccountLockedUntil is set when this failure locked the account for the client IP.
+	AccountLockedUntil *time.Time
+	// IPLockedUntil is set when this failure locked the IP.
+	IPLockedUntil *time.Time
+}
+
+// Guard applies a Policy to the counters in a Store.
+type Guard struct {
+	Store  Store
+	Policy Policy
+}
+
+// New creates a guard enforcing policy with counters in store.
+func New(store Store, policy Policy) *Guard {
+	return &Guard{Store: store, Policy: policy}
+}
+
+// AccountKey returns the counter key for the account with the given email. Emails without an
+// account are counted too, so responses do not reveal which ones exist.
+func AccountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// AccountIPKey returns the counter key for attempts on the account with the given email from ip.
+// It starts with the account's key followed by "|", which emails cannot contain after the domain.
+func AccountIPKey(email string, ip string) string {
+	return AccountKey(email) + "|" + IPKey(ip)
+}
+
+// IPKey returns the counter key for a client IP.
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// IsValidKey reports whether key is an account, account and IP, or IP counter key.
+func IsValidKey(key string) bool {
+	email, ip := ParseKey(key)
+	return email != "" || ip != ""
+}
+
+// ParseKey returns the email and IP a counter key is about; either is empty when the key does not include it.
+func ParseKey(key string) (string, string) {
+	if strings.HasPrefix(key, "ip:") {
+		return "", strings.TrimPrefix(key, "ip:")
+	}
+	if !strings.HasPrefix(key, "account:") {
+		return "", ""
+	}
+
+	email := strings.TrimPrefix(key, "account:")
+	if i := strings.LastIndex(email, "|ip:"); i >= 0 {
+		return email[:i], email[i+len("|ip:"):]
+	}
+	return email, ""
+}
+
+// limitedKey pairs a counter that locks with the failures that lock it.
+type limitedKey struct {
+	key   string
+	limit int
+}
+
+func (g *Guard) limitedKeys(email string, ip string) []limitedKey {
+	return []limitedKey{
+		{AccountIPKey(email, ip), g.Policy.MaxFailures},
+		{IPKey(ip), g.Policy.IPMaxFailures},
+	}
+}
+
+// Begin decides whether a login attempt for email from ip may go ahead at now. An allowed attempt
+// is counted against the account and IP limits up front, so concurrent attempts cannot all slip
+// under them; pass the decision to RecordFailure or Release once the outcome is known.
+func (g *Guard) Begin(ctx context.Context, email string, ip string, now time.Time) (Decision, error) {
+	decision := Decision{Allowed: true}
+
+	// Delays slow down guessing one account from anywhere; the account itself is never locked
+	account, err := g.Store.Get(ctx, AccountKey(email))
+	if err != nil {
+		return decision, err
+	}
+	if account.Failures > 0 && !account.LastFailureAt.Before(now.Add(-g.Policy.Window)) {
+		if retryAt := account.LastFailureAt.Add(g.Policy.Delay(account.Failures)); retryAt.After(now) {
+			decision.block(retryAt, false)
+		}
+	}
+
+	for _, limited := range g.limitedKeys(email, ip) {
+		attempts, err := g.Store.Get(ctx, limited.key)
+		if err != nil {
+			return Decision{Allowed: true}, err
+		}
+		if attempts.LockedUntil != nil && attempts.LockedUntil.After(now) {
+			decision.block(*attempts.LockedUntil, true)
+		}
+	}
+
+	if !decision.Allowed {
+		return decision, nil
+	}
+
+	windowStart := now.Add(-g.Policy.Window)
+	for _, limited := range g.limitedKeys(email, ip) {
+		attempts, err := g.Store.RecordFailure(ctx, limited.key, now, windowStart)
+		if err != nil {
+			g.rollback(ctx, decision, now)
+			return Decision{Allowed: true}, err
+		}
+		decision.reserved = append(decision.reserved, limited.key)
+
+		if limited.limit <= 0 || attempts.Failures < limited.limit {
+			continue
+		}
+
+		// The last attempt the limit allows locks the key straight away, so nothing else runs
+		// alongside it; only one attempt wins the lock
+		until := now.Add(g.Policy.LockoutFor(attempts.Failures, limited.limit))
+		claimed, err := g.Store.Claim(ctx, limited.key, now, until)
+		if err != nil {
+			g.rollback(ctx, decision, now)
+			return Decision{Allowed: true}, err
+		}
+		if !claimed {
+			g.rollback(ctx, decision, now)
+			return g.blockedBy(ctx, limited.key, now), nil
+		}
+
+		if decision.claimed == nil {
+			decision.claimed = map[string]time.Time{}
+		}
+		decision.claimed[limited.key] = until
+	}
+
+	return decision, nil
+}
+
+// Helper function: Refuse an attempt that lost the last try before a lockout to another one
+func (g *Guard) blockedBy(ctx context.Context, key string, now time.Time) Decision {
+	decision := Decision{Allowed: true}
+
+	attempts, err := g.Store.Get(ctx, key)
+	if err == nil && attempts.LockedUntil != nil && attempts.LockedUntil.After(now) {
+		decision.block(*attempts.LockedUntil, true)
+	} else {
+		decision.block(now.Add(time.Second), false)
+	}
+	return decision
+}
+
+// Helper function: Take back what Begin counted and lift the locks it claimed
+func (g *Guard) rollback(ctx context.Context, decision Decision, now time.Time) error {
+	var firstErr error
+	for _, key := range decision.reserved {
+		if err := g.Store.Release(ctx, key); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for key := range decision.claimed {
+		if err := g.Store.Lock(ctx, key, now); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// expired reports whether the counter no longer matters: its last failure and lockout both ended
+// before windowStart.
+func (a Attempts) expired(windowStart time.Time) bool {
+	if a.LockedUntil != nil && !a.LockedUntil.Before(windowStart) {
+		return false
+	}
+	return a.LastFailureAt.Before(windowStart)
+}
+
+func (d *Decision) block(retryAt time.Time, locked bool) {
+	d.Allowed = false
+	if retryAt.After(d.RetryAt) {
+		d.RetryAt = retryAt
+		d.Locked = locked
+	}
+}
+
+// RecordFailure records that an attempt allowed by Begin failed. It adds to the account's delay;
+// the account and IP limits already counted the attempt, and any lockout it reached is reported.
+func (g *Guard) RecordFailure(ctx context.Context, email string, ip string, decision Decision, now time.Time) (Failure, error) {
+	var failure Failure
+
+	account, err := g.Store.RecordFailure(ctx, AccountKey(email), now, now.Add(-g.Policy.Window))
+	if err != nil {
+		return failure, err
+	}
+	failure.Failures = account.Failures
+
+	if until, ok := decision.claimed[AccountIPKey(email, ip)]; ok {
+		failure.AccountLockedUntil = &until
+	}
+	if until, ok := decision.claimed[IPKey(ip)]; ok {
+		failure.IPLockedUntil = &until
+	}
+	return failure, nil
+}
+
+// Release takes back an attempt allowed by Begin that did not fail, e.g. a correct password that
+// still needs a second factor, lifting any lockout it claimed.
+func (g *Guard) Release(ctx context.Context, decision Decision, now time.Time) error {
+	return g.rollback(ctx, decision, now)
+}
+
+// RecordSuccess forgets the failures of the account, and of the account from ip, after a login
+// succeeded; the attempt itself must have been released first. Failures from other IPs are kept,
+// and so is the IP counter, so one known password cannot be used to keep guessing others.
+func (g *Guard) RecordSuccess(ctx context.Context, email string, ip string) error {
+	if err := g.Store.Reset(ctx, AccountKey(email)); err != nil {
+		return err
+	}
+	return g.Store.Reset(ctx, AccountIPKey(email, ip))
+}
+
+// Status returns the counter of the account with the given email. Its LockedUntil is the latest
+// lockout of the account for any IP that is active at now.
+func (g *Guard) Status(ctx context.Context, email string, now time.Time) (Attempts, error) {
+	account, err := g.Store.Get(ctx, AccountKey(email))
+	if err != nil {
+		return account, err
+	}
+
+	locked, err := g.Store.Locked(ctx, now)
+	if err != nil {
+		return account, err
+	}
+	prefix := AccountKey(email) + "|"
+	for _, attempts := range locked {
+		if strings.HasPrefix(attempts.Key, prefix) && (account.LockedUntil == nil || attempts.LockedUntil.After(*account.LockedUntil)) {
+			account.LockedUntil = attempts.LockedUntil
+		}
+	}
+	return account, nil
+}
+
+// Locked returns every account and IP locked at now.
+func (g *Guard) Locked(ctx context.Context, now time.Time) ([]Attempts, error) {
+	return g.Store.Locked(ctx, now)
+}
+
+// Unlock lifts the lockout of a key and forgets its failures. Unlocking an account key also unlocks
+// the account for every IP.
+func (g *Guard) Unlock(ctx context.Context, key string) error {
+	if err := g.Store.Reset(ctx, key); err != nil {
+		return err
+	}
+	if email, ip := ParseKey(key); email != "" && ip == "" {
+		return g.Store.ResetPrefix(ctx, key+"|")
+	}
+	return nil
+}
+
+// Prune deletes counters that no longer affect anything.
+func (g *Guard) Prune(ctx context.Context, now time.Time) error {
+	return g.Store.Prune(ctx, now.Add(-g.Policy.Window))
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultGuard *Guard
+	defaultMu    sync.RWMutex
+)
+
+// Default returns the process-wide guard configured by the LOGIN_* environment variables, with
+// counters in the database unless LOGIN_GUARD_STORE is memory.
+func Default() *Guard {
+	defaultOnce.Do(func() {
+		config := initializers.LoginGuard()
+
+		var store Store
+		if config.Store == initializers.LoginGuardMemory {
+			store = NewMemoryStore()
+		} else {
+			store = NewDatabaseStore(initializers.DB)
+		}
+
+		guard := New(store, Policy{
+			MaxFailures:   config.MaxFailures,
+			IPMaxFailures: config.IPMaxFailures,
+			Lockout:       config.Lockout,
+			Window:        config.Window,
+		})
+
+		defaultMu.Lock()
+		if defaultGuard == nil {
+			defaultGuard = guard
+		}
+		defaultMu.Unlock()
+	})
+
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultGuard
+}
+
+// SetDefault replaces the process-wide guard, e.g. with one using a MemoryStore in tests.
+func SetDefault(g *Guard) {
+	defaultOnce.Do(func() {})
+
+	defaultMu.Lock()
+	defaultGuard = g
+	defaultMu.Unlock()
+}
@@ -0,0 +1,433 @@
+package loginguard
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// start is the fake clock's origin; tests move now forward explicitly.
+var start = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// pause is long enough to wait out any delay between attempts.
+const pause = maxDelay + time.Second
+
+func newTestGuard(policy Policy) *Guard {
+	return New(NewMemoryStore(), policy)
+}
+
+// fail makes a failed attempt that the guard must allow.
+func fail(t *testing.T, g *Guard, email string, ip string, now time.Time) Failure {
+	t.Helper()
+	ctx := context.Background()
+
+	decision, err := g.Begin(ctx, email, ip, now)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if !decision.Allowed {
+		t.Fatalf("attempt for %s from %s at %v refused until %v", email, ip, now.Sub(start), decision.RetryAt.Sub(start))
+	}
+
+	failure, err := g.RecordFailure(ctx, email, ip, decision, now)
+	if err != nil {
+		t.Fatalf("RecordFailure: %v", err)
+	}
+	return failure
+}
+
+// begin returns the guard's decision for an attempt without settling it.
+func begin(t *testing.T, g *Guard, email string, ip string, now time.Time) Decision {
+	t.Helper()
+	decision, err := g.Begin(context.Background(), email, ip, now)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	return decision
+}
+
+func TestPolicyDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+		{7, 16 * time.Second},
+		{8, maxDelay},
+		{100, maxDelay},
+	}
+
+	var policy Policy
+	for _, tt := range tests {
+		if got := policy.Delay(tt.failures); got != tt.want {
+			t.Errorf("Delay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyLockoutFor(t *testing.T) {
+	policy := Policy{Lockout: 15 * time.Minute}
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{10, 15 * time.Minute},
+		{11, 30 * time.Minute},
+		{12, time.Hour},
+		{16, 16 * time.Hour},
+		{17, maxLockout},
+		{100, maxLockout},
+	}
+
+	for _, tt := range tests {
+		if got := policy.LockoutFor(tt.failures, 10); got != tt.want {
+			t.Errorf("LockoutFor(%d, 10) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestBeginDelaysAccount(t *testing.T) {
+	g := newTestGuard(Policy{Window: time.Hour})
+	now := start
+
+	for i := 0; i < freeFailures; i++ {
+		fail(t, g, "a@example.com", "192.0.2.1", now)
+	}
+
+	// The delay applies to the account from every IP, but is not a lockout
+	for _, ip := range []string{"192.0.2.1", "198.51.100.1"} {
+		decision := begin(t, g, "a@example.com", ip, now)
+		if decision.Allowed || decision.Locked {
+			t.Fatalf("from %s: got allowed=%v locked=%v, want a delay", ip, decision.Allowed, decision.Locked)
+		}
+		if want := now.Add(time.Second); !decision.RetryAt.Equal(want) {
+			t.Fatalf("from %s: RetryAt %v, want %v", ip, decision.RetryAt.Sub(start), want.Sub(start))
+		}
+	}
+
+	// Other accounts are unaffected, and the account may try again once the delay is over
+	if decision := begin(t, g, "b@example.com", "192.0.2.1", now); !decision.Allowed {
+		t.Fatal("other account was delayed")
+	}
+	fail(t, g, "a@example.com", "192.0.2.1", now.Add(time.Second))
+}
+
+func TestAccountLockedPerIP(t *testing.T) {
+	g := newTestGuard(Policy{MaxFailures: 3, Lockout: time.Minute, Window: time.Hour})
+	now := start
+
+	var failure Failure
+	for i := 1; i <= 3; i++ {
+		failure = fail(t, g, "a@example.com", "192.0.2.1", now)
+		if i < 3 && failure.AccountLockedUntil != nil {
+			t.Fatalf("locked after %d failures", i)
+		}
+		now = now.Add(pause)
+	}
+	if failure.AccountLockedUntil == nil {
+		t.Fatal("third failure did not lock the account")
+	}
+	if failure.IPLockedUntil != nil {
+		t.Fatal("IP locked with IP lockouts disabled")
+	}
+
+	decision := begin(t, g, "a@example.com", "192.0.2.1", now)
+	if decision.Allowed || !decision.Locked || !decision.RetryAt.Equal(*failure.AccountLockedUntil) {
+		t.Fatalf("same IP: got allowed=%v locked=%v, want locked until %v", decision.Allowed, decision.Locked, failure.AccountLockedUntil.Sub(start))
+	}
+
+	// Someone else guessing cannot keep the owner out
+	owner := begin(t, g, "a@example.com", "198.51.100.1", now)
+	if !owner.Allowed {
+		t.Fatal("account locked for another IP")
+	}
+
+	status, err := g.Status(context.Background(), "a@example.com", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Failures != 3 || status.LockedUntil == nil || !status.LockedUntil.Equal(*failure.AccountLockedUntil) {
+		t.Fatalf("Status = %d failures locked until %v", status.Failures, status.LockedUntil)
+	}
+}
+
+func TestIPLocked(t *testing.T) {
+	g := newTestGuard(Policy{IPMaxFailures: 3, Lockout: time.Minute, Window: time.Hour})
+	now := start
+
+	var failure Failure
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		failure = fail(t, g, email, "192.0.2.1", now)
+	}
+	if failure.IPLockedUntil == nil {
+		t.Fatal("third failure did not lock the IP")
+	}
+	if failure.AccountLockedUntil != nil {
+		t.Fatal("account locked with account lockouts disabled")
+	}
+
+	if decision := begin(t, g, "d@example.com", "192.0.2.1", now); decision.Allowed || !decision.Locked {
+		t.Fatal("locked IP allowed to try another account")
+	}
+	if decision := begin(t, g, "d@example.com", "198.51.100.1", now); !decision.Allowed {
+		t.Fatal("other IP was locked")
+	}
+
+	locked, err := g.Locked(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locked) != 1 || locked[0].Key != IPKey("192.0.2.1") {
+		t.Fatalf("Locked = %+v, want only the IP", locked)
+	}
+}
+
+func TestLockoutDoubles(t *testing.T) {
+	g := newTestGuard(Policy{MaxFailures: 2, Lockout: time.Minute, Window: time.Hour})
+	email, ip := "a@example.com", "192.0.2.1"
+	now := start
+
+	fail(t, g, email, ip, now)
+	now = now.Add(pause)
+
+	// Each failure once the last lockout ends locks for twice as long
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute} {
+		failure := fail(t, g, email, ip, now)
+		if failure.AccountLockedUntil == nil {
+			t.Fatalf("failure at %v did not lock", now.Sub(start))
+		}
+		if got := failure.AccountLockedUntil.Sub(now); got != want {
+			t.Fatalf("locked for %v, want %v", got, want)
+		}
+
+		if decision := begin(t, g, email, ip, failure.AccountLockedUntil.Add(-time.Second)); decision.Allowed {
+			t.Fatal("allowed before the lockout ended")
+		}
+		now = *failure.AccountLockedUntil
+	}
+
+	// A window without failures after the lockout ended starts the count over
+	now = now.Add(time.Hour + time.Second)
+	if failure := fail(t, g, email, ip, now); failure.AccountLockedUntil != nil {
+		t.Fatal("first failure after the window locked again")
+	}
+	now = now.Add(pause)
+	failure := fail(t, g, email, ip, now)
+	if failure.AccountLockedUntil == nil || failure.AccountLockedUntil.Sub(now) != time.Minute {
+		t.Fatalf("lockout after the window = %v, want back to %v", failure.AccountLockedUntil, time.Minute)
+	}
+}
+
+func TestBeginReservesConcurrentAttempts(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		emails  func(i int) string
+		allowed int
+	}{
+		{
+			name:    "account from one IP",
+			policy:  Policy{MaxFailures: 5, Lockout: time.Minute, Window: time.Hour},
+			emails:  func(i int) string { return "a@example.com" },
+			allowed: 5,
+		},
+		{
+			name:    "IP across accounts",
+			policy:  Policy{IPMaxFailures: 7, Lockout: time.Minute, Window: time.Hour},
+			emails:  func(i int) string { return string(rune('a'+i%26)) + "@example.com" },
+			allowed: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		g := newTestGuard(tt.policy)
+		ctx := context.Background()
+
+		// Every attempt starts before any of them fails, as with parallel requests
+		decisions := make([]Decision, 50)
+		var wg sync.WaitGroup
+		for i := range decisions {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				decision, err := g.Begin(ctx, tt.emails(i), "192.0.2.1", start)
+				if err != nil {
+					t.Error(err)
+				}
+				decisions[i] = decision
+			}(i)
+		}
+		wg.Wait()
+
+		allowed, locks := 0, 0
+		for i, decision := range decisions {
+			if !decision.Allowed {
+				continue
+			}
+			allowed++
+
+			failure, err := g.RecordFailure(ctx, tt.emails(i), "192.0.2.1", decision, start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if failure.AccountLockedUntil != nil || failure.IPLockedUntil != nil {
+				locks++
+			}
+		}
+
+		if allowed != tt.allowed {
+			t.Errorf("%s: %d concurrent attempts allowed, want %d", tt.name, allowed, tt.allowed)
+		}
+		if locks != 1 {
+			t.Errorf("%s: %d failures reported a lockout, want 1", tt.name, locks)
+		}
+	}
+}
+
+func TestReleaseTakesBackAttempt(t *testing.T) {
+	g := newTestGuard(Policy{MaxFailures: 3, IPMaxFailures: 3, Lockout: time.Minute, Window: time.Hour})
+	ctx := context.Background()
+	email, ip := "a@example.com", "192.0.2.1"
+
+	// Correct passwords that go on to a second factor never add up to a lockout
+	for i := 0; i < 10; i++ {
+		decision := begin(t, g, email, ip, start)
+		if !decision.Allowed {
+			t.Fatalf("attempt %d refused", i+1)
+		}
+		if err := g.Release(ctx, decision, start); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, key := range []string{AccountIPKey(email, ip), IPKey(ip)} {
+		attempts, _ := g.Store.Get(ctx, key)
+		if attempts.Failures != 0 {
+			t.Errorf("%s has %d failures after releasing every attempt", key, attempts.Failures)
+		}
+	}
+}
+
+func TestReleaseLiftsClaimedLockout(t *testing.T) {
+	g := newTestGuard(Policy{MaxFailures: 3, Lockout: time.Minute, Window: time.Hour})
+	ctx := context.Background()
+	email, ip := "a@example.com", "192.0.2.1"
+	now := start
+
+	fail(t, g, email, ip, now)
+	now = now.Add(pause)
+	fail(t, g, email, ip, now)
+	now = now.Add(pause)
+
+	// The last attempt before the limit locks everything else out while it runs
+	last := begin(t, g, email, ip, now)
+	if !last.Allowed {
+		t.Fatal("last attempt refused")
+	}
+	if concurrent := begin(t, g, email, ip, now); concurrent.Allowed || !concurrent.Locked {
+		t.Fatal("attempt alongside the last one was allowed")
+	}
+
+	// It was the right password, so the lockout it claimed goes away
+	if err := g.Release(ctx, last, now); err != nil {
+		t.Fatal(err)
+	}
+	if next := begin(t, g, email, ip, now); !next.Allowed {
+		t.Fatal("still locked after releasing the last attempt")
+	}
+}
+
+func TestRecordSuccessResets(t *testing.T) {
+	g := newTestGuard(Policy{MaxFailures: 5, IPMaxFailures: 50, Lockout: time.Minute, Window: time.Hour})
+	ctx := context.Background()
+	email := "a@example.com"
+	now := start
+
+	fail(t, g, email, "192.0.2.1", now)
+	fail(t, g, email, "192.0.2.1", now)
+	fail(t, g, email, "198.51.100.1", now)
+	now = now.Add(pause)
+
+	decision := begin(t, g, email, "192.0.2.1", now)
+	if err := g.Release(ctx, decision, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.RecordSuccess(ctx, email, "192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{AccountKey(email), 0},
+		{AccountIPKey(email, "192.0.2.1"), 0},
+		// Failures from elsewhere, and the IP's own count, are kept
+		{AccountIPKey(email, "198.51.100.1"), 1},
+		{IPKey("192.0.2.1"), 2},
+	}
+	for _, tt := range tests {
+		attempts, err := g.Store.Get(ctx, tt.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if attempts.Failures != tt.want {
+			t.Errorf("%s: %d failures, want %d", tt.key, attempts.Failures, tt.want)
+		}
+	}
+}
+
+func TestUnlockAccountForEveryIP(t *testing.T) {
+	g := newTestGuard(Policy{MaxFailures: 1, Lockout: time.Minute, Window: time.Hour})
+	ctx := context.Background()
+
+	fail(t, g, "a@example.com", "192.0.2.1", start)
+	fail(t, g, "a@example.com", "198.51.100.1", start.Add(time.Second))
+	fail(t, g, "ab@example.com", "192.0.2.1", start.Add(2*time.Second))
+
+	if err := g.Unlock(ctx, AccountKey("A@example.com")); err != nil {
+		t.Fatal(err)
+	}
+
+	locked, err := g.Locked(ctx, start.Add(3*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locked) != 1 || locked[0].Key != AccountIPKey("ab@example.com", "192.0.2.1") {
+		t.Fatalf("Locked = %+v, want only the other account", locked)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		email string
+		ip    string
+		valid bool
+	}{
+		{AccountKey(" John@Example.com "), "john@example.com", "", true},
+		{AccountIPKey("john@example.com", "203.0.113.7"), "john@example.com", "203.0.113.7", true},
+		{AccountIPKey("john@example.com", "2001:db8::1"), "john@example.com", "2001:db8::1", true},
+		{IPKey("203.0.113.7"), "", "203.0.113.7", true},
+		{"account:", "", "", false},
+		{"ip:", "", "", false},
+		{"user:1", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tt := range tests {
+		email, ip := ParseKey(tt.key)
+		if email != tt.email || ip != tt.ip {
+			t.Errorf("ParseKey(%q) = %q, %q, want %q, %q", tt.key, email, ip, tt.email, tt.ip)
+		}
+		if got := IsValidKey(tt.key); got != tt.valid {
+			t.Errorf("IsValidKey(%q) = %v, want %v", tt.key, got, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package loginguard
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps counters in memory. Each instance counts on its own, so it only fully
+// protects single-instance deployments; it is also handy in tests.
+type MemoryStore struct {
+	mu       sync.Mutex
+	attempts map[string]Attempts
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{attempts: map[string]Attempts{}}
+}
+
+// Get returns the counter for key.
+func (s *MemoryStore) Get(ctx context.Context, key string) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempts, ok := s.attempts[key]; ok {
+		return attempts, nil
+	}
+	return Attempts{Key: key}, nil
+}
+
+// RecordFailure adds a failure to the counter for key.
+func (s *MemoryStore) RecordFailure(ctx context.Context, key string, now time.Time, windowStart time.Time) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts, ok := s.attempts[key]
+	if !ok || attempts.expired(windowStart) {
+		attempts = Attempts{Key: key, LockedUntil: attempts.LockedUntil}
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = now
+
+	s.attempts[key] = attempts
+	return attempts, nil
+}
+
+// Release takes back one failure from the counter for key.
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempts, ok := s.attempts[key]; ok && attempts.Failures > 0 {
+		attempts.Failures--
+		s.attempts[key] = attempts
+	}
+	return nil
+}
+
+// Lock refuses logins for key until the given time.
+func (s *MemoryStore) Lock(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts, ok := s.attempts[key]
+	if !ok {
+		attempts = Attempts{Key: key}
+	}
+	attempts.LockedUntil = &until
+
+	s.attempts[key] = attempts
+	return nil
+}
+
+// Claim locks key until the given time unless it is locked at now.
+func (s *MemoryStore) Claim(ctx context.Context, key string, now time.Time, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts, ok := s.attempts[key]
+	if !ok {
+		attempts = Attempts{Key: key}
+	}
+	if attempts.LockedUntil != nil && attempts.LockedUntil.After(now) {
+		return false, nil
+	}
+	attempts.LockedUntil = &until
+
+	s.attempts[key] = attempts
+	return true, nil
+}
+
+// Reset forgets the counter for key.
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+// ResetPrefix forgets every counter whose key starts with prefix.
+func (s *MemoryStore) ResetPrefix(ctx context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.attempts {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.attempts, key)
+		}
+	}
+	return nil
+}
+
+// Locked returns the counters locked at now.
+func (s *MemoryStore) Locked(ctx context.Context, now time.Time) ([]Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	locked := []Attempts{}
+	for _, attempts := range s.attempts {
+		if attempts.LockedUntil != nil && attempts.LockedUntil.After(now) {
+			locked = append(locked, attempts)
+		}
+	}
+
+	sort.Slice(locked, func(i, j int) bool {
+		return locked[i].LockedUntil.After(*locked[j].LockedUntil)
+	})
+	return locked, nil
+}
+
+// Prune deletes counters whose last failure and lockout both ended before the window.
+func (s *MemoryStore) Prune(ctx context.Context, windowStart time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, attempts := range s.attempts {
+		if attempts.expired(windowStart) {
+			delete(s.attempts, key)
+		}
+	}
+	return nil
+}
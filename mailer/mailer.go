@@ -0,0 +1,68 @@
+package mailer
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+)
+
+// Message is an email with a plain text body and an optional HTML alternative.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends email. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultMailer Mailer
+	defaultMu     sync.RWMutex
+)
+
+// Default returns the process-wide mailer: SMTP when SMTP_HOST is configured, otherwise
+// an in-memory sink that keeps messages without delivering them.
+func Default() Mailer {
+	defaultOnce.Do(func() {
+		config := initializers.Mail()
+
+		var m Mailer
+		if config.Host != "" {
+			m = NewSMTPMailer(config)
+		} else {
+			log.Println("SMTP_HOST is not set; emails are kept in memory and not delivered")
+			m = NewMemoryMailer()
+		}
+
+		defaultMu.Lock()
+		if defaultMailer == nil {
+			defaultMailer = m
+		}
+		defaultMu.Unlock()
+	})
+
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultMailer
+}
+
+// SetDefault replaces the process-wide mailer, e.g. with a MemoryMailer in tests.
+func SetDefault(m Mailer) {
+	defaultOnce.Do(func() {})
+
+	defaultMu.Lock()
+	defaultMailer = m
+	defaultMu.Unlock()
+}
+
+// Send sends a message through the default mailer.
+func Send(ctx context.Context, message Message) error {
+	return Default().Send(ctx, message)
+}
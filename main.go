@@ -2,13 +2,17 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/auth"
 	"github.com/olujimiAdebakin/Shurl/controllers"
 	_ "github.com/olujimiAdebakin/Shurl/docs"
 	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/jobs"
 	"github.com/olujimiAdebakin/Shurl/middleware"
+	"github.com/olujimiAdebakin/Shurl/models"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -39,6 +43,9 @@ func main() {
 
 	// Initialize router
 	router := gin.Default()
+	if err := router.SetTrustedProxies(initializers.TrustedProxies()); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
 	router.Use(middleware.CORSMiddleware())
 
 	// Health check endpoint (define before wildcards to avoid conflicts)
@@ -88,6 +95,50 @@ func main() {
 		// @Router /users/login [post]
 		users.POST("/login", controllers.LoginWithToken)
 
+		// @Summary Complete Two-Factor Login
+		// @Description Exchange the login challenge token and an authenticator or recovery code for tokens
+		// @Tags Authentication
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.TwoFactorLoginRequest true "Challenge token and code"
+		// @Success 200 {object} dtos.LoginResponse "User authenticated with token"
+		// @Failure 401 {object} map[string]interface{} "Invalid code or challenge"
+		// @Router /users/login/2fa [post]
+		users.POST("/login/2fa", controllers.VerifyTwoFactorLogin)
+
+		// @Summary Refresh Token
+		// @Description Exchange a single-use refresh token for a new access token and refresh token
+		// @Tags Authentication
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.RefreshTokenRequest true "Refresh token"
+		// @Success 200 {object} dtos.LoginResponse "New tokens"
+		// @Failure 400 {object} map[string]interface{} "Bad request"
+		// @Failure 401 {object} map[string]interface{} "Invalid, expired or reused refresh token"
+		// @Router /users/refresh [post]
+		users.POST("/refresh", controllers.RefreshToken)
+
+		// @Summary Logout
+		// @Description Revoke the current access token and its session's refresh token
+		// @Tags Authentication
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {object} map[string]interface{} "Logged out"
+		// @Failure 400 {object} map[string]interface{} "Token cannot be revoked on its own (API key or token without an ID)"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /users/logout [post]
+		users.POST("/logout", middleware.RequireAuthWithToken, controllers.Logout)
+
+		// @Summary Logout All Sessions
+		// @Description Revoke every access and refresh token of the user on all devices
+		// @Tags Authentication
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {object} map[string]interface{} "Logged out of all sessions"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /users/logout-all [post]
+		users.POST("/logout-all", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAccount), controllers.LogoutAll)
+
 		// @Summary Validate Token
 		// @Description Verify JWT token and get user information
 		// @Tags Authentication
@@ -98,21 +149,172 @@ func main() {
 		// @Failure 401 {object} map[string]interface{} "Invalid or missing token"
 		// @Router /users/validate [get]
 		users.GET("/validate", middleware.RequireAuthWithToken, controllers.Validate)
+
+		// @Summary Verify Email
+		// @Description Confirm the email address with the token from the verification email
+		// @Tags Authentication
+		// @Produce json
+		// @Param token query string true "Verification token"
+		// @Success 200 {object} map[string]interface{} "Email verified"
+		// @Failure 400 {object} map[string]interface{} "Invalid or expired link"
+		// @Router /users/verify-email [get]
+		users.GET("/verify-email", controllers.VerifyEmail)
+
+		// @Summary Resend Verification Email
+		// @Description Send a new verification link to the authenticated user (at most once a minute)
+		// @Tags Authentication
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {object} map[string]interface{} "Verification email sent"
+		// @Failure 429 {object} map[string]interface{} "Too many requests"
+		// @Router /users/verify-email/resend [post]
+		users.POST("/verify-email/resend", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAccount), controllers.ResendVerificationEmail)
+
+		// @Summary Forgot Password
+		// @Description Email a single-use password reset token (same response whether or not the account exists)
+		// @Tags Authentication
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.ForgotPasswordRequest true "Account email"
+		// @Success 200 {object} map[string]interface{} "Reset email sent if the account exists"
+		// @Router /users/password/forgot [post]
+		users.POST("/password/forgot", controllers.ForgotPassword)
+
+		// @Summary Reset Password
+		// @Description Set a new password with a reset token and log out every session
+		// @Tags Authentication
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.ResetPasswordRequest true "Reset token and new password"
+		// @Success 200 {object} map[string]interface{} "Password reset"
+		// @Failure 400 {object} map[string]interface{} "Invalid or expired token"
+		// @Router /users/password/reset [post]
+		users.POST("/password/reset", controllers.ResetPassword)
+
+		// @Summary Change Password
+		// @Description Change the password with the current one; other sessions are logged out
+		// @Tags Authentication
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.ChangePasswordRequest true "Current and new password"
+		// @Success 200 {object} dtos.LoginResponse "New tokens for this session"
+		// @Failure 401 {object} map[string]interface{} "Current password is incorrect"
+		// @Router /users/password/change [post]
+		users.POST("/password/change", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAccount), controllers.ChangePassword)
+
+		// @Summary Get Profile
+		// @Description Retrieve the authenticated user's profile
+		// @Tags Users
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {object} dtos.ProfileResponse "Profile"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /users/me [get]
+		users.GET("/me", middleware.RequireAuthWithToken, controllers.GetProfile)
+
+		// @Summary Update Profile
+		// @Description Change name and email; a new email must be verified again
+		// @Tags Users
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.UpdateProfileRequest true "Fields to change"
+		// @Success 200 {object} dtos.ProfileResponse "Updated profile"
+		// @Failure 409 {object} map[string]interface{} "Email already in use"
+		// @Router /users/me [patch]
+		users.PATCH("/me", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAccount), controllers.UpdateProfile)
+
+		// @Summary Delete Account
+		// @Description Delete the account after confirming the password; links are deleted or anonymized per ACCOUNT_DELETION_POLICY
+		// @Tags Users
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.DeleteAccountRequest true "Password confirmation"
+		// @Success 200 {object} map[string]interface{} "Account deleted"
+		// @Failure 401 {object} map[string]interface{} "Password is incorrect"
+		// @Router /users/me [delete]
+		users.DELETE("/me", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAccount), controllers.DeleteAccount)
+
+		// @Summary Two-Factor Status
+		// @Description Whether two-factor authentication is enabled and how many recovery codes are left
+		// @Tags Two-Factor Authentication
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {object} dtos.TwoFactorStatusResponse "Status"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /users/2fa [get]
+		users.GET("/2fa", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAccount), controllers.GetTwoFactorStatus)
+
+		// @Summary Enrol Two-Factor
+		// @Description Confirm the password and generate a TOTP secret, otpauth URI and QR code for an authenticator app
+		// @Tags Two-Factor Authentication
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.EnrollTwoFactorRequest true "Current password"
+		// @Success 200 {object} dtos.TwoFactorEnrollResponse "Secret, otpauth URI and QR code"
+		// @Failure 401 {object} map[string]interface{} "Password is incorrect"
+		// @Failure 409 {object} map[string]interface{} "Already enabled"
+		// @Router /users/2fa/enroll [post]
+		users.POST("/2fa/enroll", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAccount), controllers.EnrollTwoFactor)
+
+		// @Summary Confirm Two-Factor
+		// @Description Enable two-factor authentication with the password and a code, and receive recovery codes
+		// @Tags Two-Factor Authentication
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.ConfirmTwoFactorRequest true "Password and code from the authenticator app"
+		// @Success 200 {object} dtos.RecoveryCodesResponse "Recovery codes"
+		// @Failure 400 {object} map[string]interface{} "Invalid code"
+		// @Failure 401 {object} map[string]interface{} "Password is incorrect"
+		// @Router /users/2fa/confirm [post]
+		users.POST("/2fa/confirm", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAccount), controllers.ConfirmTwoFactor)
+
+		// @Summary Disable Two-Factor
+		// @Description Disable two-factor authentication with the password and a code
+		// @Tags Two-Factor Authentication
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.DisableTwoFactorRequest true "Password and code"
+		// @Success 200 {object} map[string]interface{} "Disabled"
+		// @Failure 401 {object} map[string]interface{} "Invalid password or code"
+		// @Router /users/2fa/disable [post]
+		users.POST("/2fa/disable", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAccount), controllers.DisableTwoFactor)
+
+		// @Summary Regenerate Recovery Codes
+		// @Description Replace the recovery codes after confirming a code
+		// @Tags Two-Factor Authentication
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.TwoFactorCodeRequest true "Authenticator or recovery code"
+		// @Success 200 {object} dtos.RecoveryCodesResponse "New recovery codes"
+		// @Failure 401 {object} map[string]interface{} "Invalid code"
+		// @Router /users/2fa/recovery-codes [post]
+		users.POST("/2fa/recovery-codes", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAccount), controllers.RegenerateRecoveryCodes)
 	}
 
 	// Link routes
 	links := v1.Group("/links")
 	{
 		// @Summary Get Link Info
-		// @Description Retrieve link details and increment click count
+		// @Description Retrieve full link details without counting a click (owner only)
 		// @Tags Links
+		// @Security Bearer
 		// @Accept json
 		// @Produce json
 		// @Param shortCode path string true "Short code of the link"
+		// @Param domain query string false "Custom domain the short code belongs to"
 		// @Success 200 {object} dtos.LinkResponse "Link information"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
 		// @Failure 404 {object} map[string]interface{} "Link not found"
 		// @Router /links/{shortCode} [get]
-		links.GET("/:shortCode", controllers.GetLink)
+		links.GET("/:shortCode", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksRead), controllers.GetLink)
 
 		// @Summary Create Link
 		// @Description Create a new shortened URL
@@ -124,9 +326,10 @@ func main() {
 		// @Success 201 {object} dtos.LinkResponse "Link created"
 		// @Failure 400 {object} map[string]interface{} "Bad request"
 		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Failure 403 {object} map[string]interface{} "Email not verified (when REQUIRE_EMAIL_VERIFICATION is on)"
 		// @Failure 409 {object} map[string]interface{} "Short code already exists"
 		// @Router /links [post]
-		links.POST("", middleware.RequireAuthWithToken, controllers.CreateLink)
+		links.POST("", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksWrite), middleware.RequireVerifiedEmail, controllers.CreateLink)
 
 		// @Summary Update Link
 		// @Description Modify an existing link (owner only)
@@ -142,7 +345,7 @@ func main() {
 		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
 		// @Failure 404 {object} map[string]interface{} "Link not found"
 		// @Router /links/{shortCode} [patch]
-		links.PATCH("/:shortCode", middleware.RequireAuthWithToken, controllers.UpdateLink)
+		links.PATCH("/:shortCode", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksWrite), controllers.UpdateLink)
 
 		// @Summary Delete Link
 		// @Description Delete a link (owner only)
@@ -156,24 +359,681 @@ func main() {
 		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
 		// @Failure 404 {object} map[string]interface{} "Link not found"
 		// @Router /links/{shortCode} [delete]
-		links.DELETE("/:shortCode", middleware.RequireAuthWithToken, controllers.DeleteLink)
+		links.DELETE("/:shortCode", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksWrite), controllers.DeleteLink)
 
 		// @Summary Get User Links
-		// @Description Retrieve all links created by authenticated user
+		// @Description Retrieve all links created by authenticated user, optionally searched or filtered by tag or folder
 		// @Tags Links
 		// @Security Bearer
 		// @Accept json
 		// @Produce json
+		// @Param q query string false "Search title, description, notes, URL and short code"
+		// @Param tag query string false "Only links carrying this tag"
+		// @Param folderId query int false "Only links in this folder (0 for unfiled links)"
 		// @Success 200 {array} dtos.LinkResponse "User's links"
 		// @Failure 401 {object} map[string]interface{} "Unauthorized"
 		// @Router /links [get]
-		links.GET("", middleware.RequireAuthWithToken, controllers.GetUserLinks)
+		links.GET("", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksRead), controllers.GetUserLinks)
+
+		// @Summary Get Link Stats
+		// @Description Retrieve click statistics for a link (owner only)
+		// @Tags Links
+		// @Security Bearer
+		// @Produce json
+		// @Param shortCode path string true "Short code of the link"
+		// @Success 200 {object} dtos.LinkStatsResponse "Link statistics"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
+		// @Failure 404 {object} map[string]interface{} "Link not found"
+		// @Router /links/{shortCode}/stats [get]
+		links.GET("/:shortCode/stats", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAnalyticsRead), controllers.GetLinkStats)
+
+		// @Summary Add Link Alias
+		// @Description Add another short code that redirects to the same link (owner only)
+		// @Tags Links
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param shortCode path string true "Short code of the link"
+		// @Param request body dtos.LinkAliasRequest true "Alias to add"
+		// @Success 201 {object} dtos.LinkResponse "Alias added"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
+		// @Failure 404 {object} map[string]interface{} "Link not found"
+		// @Failure 409 {object} map[string]interface{} "Short code already exists"
+		// @Router /links/{shortCode}/aliases [post]
+		links.POST("/:shortCode/aliases", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksWrite), controllers.AddLinkAlias)
+
+		// @Summary Remove Link Alias
+		// @Description Remove an alias from a link (owner only)
+		// @Tags Links
+		// @Security Bearer
+		// @Produce json
+		// @Param shortCode path string true "Short code of the link"
+		// @Param alias path string true "Alias to remove"
+		// @Success 200 {object} dtos.LinkResponse "Alias removed"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
+		// @Failure 404 {object} map[string]interface{} "Link or alias not found"
+		// @Router /links/{shortCode}/aliases/{alias} [delete]
+		links.DELETE("/:shortCode/aliases/:alias", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksWrite), controllers.RemoveLinkAlias)
+
+		// @Summary Set Primary Alias
+		// @Description Make an alias the link's primary short code (owner only)
+		// @Tags Links
+		// @Security Bearer
+		// @Produce json
+		// @Param shortCode path string true "Short code of the link"
+		// @Param alias path string true "Alias to promote"
+		// @Success 200 {object} dtos.LinkResponse "Primary short code changed"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
+		// @Failure 404 {object} map[string]interface{} "Link or alias not found"
+		// @Router /links/{shortCode}/aliases/{alias}/primary [post]
+		links.POST("/:shortCode/aliases/:alias/primary", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksWrite), controllers.SetPrimaryLinkAlias)
+
+		// @Summary Share Link Stats
+		// @Description Make a link's statistics viewable without an account through an unguessable URL (owner only)
+		// @Tags Links
+		// @Security Bearer
+		// @Produce json
+		// @Param shortCode path string true "Short code of the link"
+		// @Success 200 {object} dtos.LinkStatsShareResponse "Public stats URL"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
+		// @Failure 404 {object} map[string]interface{} "Link not found"
+		// @Router /links/{shortCode}/stats/share [post]
+		links.POST("/:shortCode/stats/share", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAnalyticsRead, auth.ScopeLinksWrite), controllers.ShareLinkStats)
+
+		// @Summary Unshare Link Stats
+		// @Description Make a link's statistics private again and invalidate the public URL (owner only)
+		// @Tags Links
+		// @Security Bearer
+		// @Produce json
+		// @Param shortCode path string true "Short code of the link"
+		// @Success 200 {object} dtos.LinkStatsShareResponse "Stats are private"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
+		// @Failure 404 {object} map[string]interface{} "Link not found"
+		// @Router /links/{shortCode}/stats/share [delete]
+		links.DELETE("/:shortCode/stats/share", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksWrite), controllers.UnshareLinkStats)
+
+		// @Summary Stream All Clicks
+		// @Description Stream click events for all of the authenticated user's links as Server-Sent Events
+		// @Tags Links
+		// @Security Bearer
+		// @Produce text/event-stream
+		// @Success 200 {string} string "Event stream of click, dropped and heartbeat events"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /links/live [get]
+		links.GET("/live", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAnalyticsRead), controllers.StreamUserClicks)
+
+		// @Summary Stream Link Clicks
+		// @Description Stream click events for one link as Server-Sent Events (owner only)
+		// @Tags Links
+		// @Security Bearer
+		// @Produce text/event-stream
+		// @Param shortCode path string true "Short code of the link"
+		// @Success 200 {string} string "Event stream of click, dropped and heartbeat events"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
+		// @Failure 404 {object} map[string]interface{} "Link not found"
+		// @Router /links/{shortCode}/live [get]
+		links.GET("/:shortCode/live", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAnalyticsRead), controllers.StreamLinkClicks)
+
+		// @Summary List Broken Links
+		// @Description Retrieve the authenticated user's links whose destinations failed the last health check
+		// @Tags Links
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {array} dtos.LinkResponse "Broken links"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /links/broken [get]
+		links.GET("/broken", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksRead), controllers.GetBrokenLinks)
+
+		// @Summary List Deleted Links
+		// @Description Retrieve the authenticated user's links in the trash
+		// @Tags Links
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {array} dtos.TrashedLinkResponse "Deleted links with purge dates"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /links/trash [get]
+		links.GET("/trash", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksRead), controllers.GetTrashedLinks)
+
+		// @Summary Restore Link
+		// @Description Restore a deleted link from the trash (owner only)
+		// @Tags Links
+		// @Security Bearer
+		// @Produce json
+		// @Param shortCode path string true "Short code of the link"
+		// @Success 200 {object} dtos.LinkResponse "Link restored"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
+		// @Failure 404 {object} map[string]interface{} "Deleted link not found"
+		// @Router /links/{shortCode}/restore [post]
+		links.POST("/:shortCode/restore", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksWrite), controllers.RestoreLink)
+
+		// @Summary Purge Link
+		// @Description Permanently delete a link from the trash and release its short code (owner only)
+		// @Tags Links
+		// @Security Bearer
+		// @Produce json
+		// @Param shortCode path string true "Short code of the link"
+		// @Success 200 {object} map[string]interface{} "Link permanently deleted"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
+		// @Failure 404 {object} map[string]interface{} "Deleted link not found"
+		// @Router /links/{shortCode}/purge [delete]
+		links.DELETE("/:shortCode/purge", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksWrite), controllers.PurgeLink)
+
+		// @Summary Get Link History
+		// @Description List previous destinations of a link (owner only)
+		// @Tags Links
+		// @Security Bearer
+		// @Produce json
+		// @Param shortCode path string true "Short code of the link"
+		// @Success 200 {array} dtos.LinkRevisionResponse "Link revisions, newest first"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
+		// @Failure 404 {object} map[string]interface{} "Link not found"
+		// @Router /links/{shortCode}/history [get]
+		links.GET("/:shortCode/history", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksRead), controllers.GetLinkHistory)
+
+		// @Summary Revert Link
+		// @Description Restore a previous destination from the link history (owner only)
+		// @Tags Links
+		// @Security Bearer
+		// @Produce json
+		// @Param shortCode path string true "Short code of the link"
+		// @Param revisionId path int true "Revision to restore"
+		// @Success 200 {object} dtos.LinkResponse "Link reverted"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not the owner"
+		// @Failure 404 {object} map[string]interface{} "Link or revision not found"
+		// @Router /links/{shortCode}/history/{revisionId}/revert [post]
+		links.POST("/:shortCode/history/:revisionId/revert", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeLinksWrite), controllers.RevertLink)
+	}
+
+	// @Summary Resolve Short Code
+	// @Description Return only the destination of a short link without counting a click
+	// @Tags Redirect
+	// @Produce json
+	// @Param shortCode path string true "Short code of the link"
+	// @Param domain query string false "Custom domain the short code belongs to"
+	// @Success 200 {object} dtos.ResolveLinkResponse "Link destination"
+	// @Failure 404 {object} map[string]interface{} "Link not found"
+	// @Router /resolve/{shortCode} [get]
+	v1.GET("/resolve/:shortCode", controllers.ResolveLink)
+
+	// Tag routes
+	tags := v1.Group("/tags", middleware.RequireAuthWithToken)
+	{
+		// @Summary List Tags
+		// @Description Retrieve the authenticated user's tags with link counts
+		// @Tags Tags
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {array} dtos.TagResponse "User's tags"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /tags [get]
+		tags.GET("", middleware.RequireScope(auth.ScopeLinksRead), controllers.GetTags)
+
+		// @Summary Rename Tag
+		// @Description Rename a tag
+		// @Tags Tags
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param id path int true "Tag ID"
+		// @Param request body dtos.TagRequest true "New tag name"
+		// @Success 200 {object} dtos.TagResponse "Tag renamed"
+		// @Failure 404 {object} map[string]interface{} "Tag not found"
+		// @Failure 409 {object} map[string]interface{} "Tag name already exists"
+		// @Router /tags/{id} [patch]
+		tags.PATCH("/:id", middleware.RequireScope(auth.ScopeLinksWrite), controllers.RenameTag)
+
+		// @Summary Merge Tags
+		// @Description Move all links from one tag to another and delete the source tag
+		// @Tags Tags
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param id path int true "Source tag ID"
+		// @Param request body dtos.MergeTagRequest true "Target tag"
+		// @Success 200 {object} dtos.TagResponse "Merged tag"
+		// @Failure 404 {object} map[string]interface{} "Tag not found"
+		// @Router /tags/{id}/merge [post]
+		tags.POST("/:id/merge", middleware.RequireScope(auth.ScopeLinksWrite), controllers.MergeTag)
+
+		// @Summary Delete Tag
+		// @Description Delete a tag and detach it from all links
+		// @Tags Tags
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "Tag ID"
+		// @Success 200 {object} map[string]interface{} "Tag deleted"
+		// @Failure 404 {object} map[string]interface{} "Tag not found"
+		// @Router /tags/{id} [delete]
+		tags.DELETE("/:id", middleware.RequireScope(auth.ScopeLinksWrite), controllers.DeleteTag)
+	}
+
+	// Folder routes
+	folders := v1.Group("/folders", middleware.RequireAuthWithToken)
+	{
+		// @Summary List Folders
+		// @Description Retrieve the authenticated user's folders
+		// @Tags Folders
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {array} dtos.FolderResponse "User's folders"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /folders [get]
+		folders.GET("", middleware.RequireScope(auth.ScopeLinksRead), controllers.GetFolders)
+
+		// @Summary Create Folder
+		// @Description Create a folder, optionally nested in another folder
+		// @Tags Folders
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.FolderRequest true "Folder details"
+		// @Success 201 {object} dtos.FolderResponse "Folder created"
+		// @Failure 400 {object} map[string]interface{} "Bad request"
+		// @Router /folders [post]
+		folders.POST("", middleware.RequireScope(auth.ScopeLinksWrite), controllers.CreateFolder)
+
+		// @Summary Update Folder
+		// @Description Rename or move a folder
+		// @Tags Folders
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param id path int true "Folder ID"
+		// @Param request body dtos.FolderUpdateRequest true "Folder changes"
+		// @Success 200 {object} dtos.FolderResponse "Folder updated"
+		// @Failure 400 {object} map[string]interface{} "Bad request"
+		// @Failure 404 {object} map[string]interface{} "Folder not found"
+		// @Router /folders/{id} [patch]
+		folders.PATCH("/:id", middleware.RequireScope(auth.ScopeLinksWrite), controllers.UpdateFolder)
+
+		// @Summary Delete Folder
+		// @Description Delete a folder; its links and sub-folders move to its parent
+		// @Tags Folders
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "Folder ID"
+		// @Success 200 {object} map[string]interface{} "Folder deleted"
+		// @Failure 404 {object} map[string]interface{} "Folder not found"
+		// @Router /folders/{id} [delete]
+		folders.DELETE("/:id", middleware.RequireScope(auth.ScopeLinksWrite), controllers.DeleteFolder)
+	}
+
+	// Custom domain routes
+	domains := v1.Group("/domains", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeDomains))
+	{
+		// @Summary List Domains
+		// @Description Retrieve the authenticated user's custom domains
+		// @Tags Domains
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {array} dtos.DomainResponse "User's domains"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /domains [get]
+		domains.GET("", controllers.GetDomains)
+
+		// @Summary Register Domain
+		// @Description Register a custom domain and receive verification instructions
+		// @Tags Domains
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.DomainRequest true "Domain to register"
+		// @Success 201 {object} dtos.DomainResponse "Domain registered"
+		// @Failure 400 {object} map[string]interface{} "Bad request"
+		// @Failure 409 {object} map[string]interface{} "Domain already registered"
+		// @Router /domains [post]
+		domains.POST("", controllers.CreateDomain)
+
+		// @Summary Verify Domain
+		// @Description Check the DNS TXT record or HTTP well-known file for the verification token
+		// @Tags Domains
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "Domain ID"
+		// @Success 200 {object} dtos.DomainResponse "Domain verified"
+		// @Failure 404 {object} map[string]interface{} "Domain not found"
+		// @Failure 422 {object} map[string]interface{} "Verification failed"
+		// @Router /domains/{id}/verify [post]
+		domains.POST("/:id/verify", controllers.VerifyDomain)
+
+		// @Summary Delete Domain
+		// @Description Remove a custom domain that has no links
+		// @Tags Domains
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "Domain ID"
+		// @Success 200 {object} map[string]interface{} "Domain deleted"
+		// @Failure 404 {object} map[string]interface{} "Domain not found"
+		// @Failure 409 {object} map[string]interface{} "Domain still has links"
+		// @Router /domains/{id} [delete]
+		domains.DELETE("/:id", controllers.DeleteDomain)
+	}
+
+	// Webhook routes
+	webhookRoutes := v1.Group("/webhooks", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeWebhooks))
+	{
+		// @Summary List Webhooks
+		// @Description Retrieve the authenticated user's webhook endpoints
+		// @Tags Webhooks
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {array} dtos.WebhookResponse "User's webhooks"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /webhooks [get]
+		webhookRoutes.GET("", controllers.GetWebhooks)
+
+		// @Summary Create Webhook
+		// @Description Register an endpoint for signed event payloads
+		// @Tags Webhooks
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.CreateWebhookRequest true "Webhook details"
+		// @Success 201 {object} dtos.WebhookResponse "Webhook created, including its secret"
+		// @Failure 400 {object} map[string]interface{} "Bad request"
+		// @Router /webhooks [post]
+		webhookRoutes.POST("", controllers.CreateWebhook)
+
+		// @Summary Update Webhook
+		// @Description Change a webhook's URL or events, or pause it
+		// @Tags Webhooks
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param id path int true "Webhook ID"
+		// @Param request body dtos.UpdateWebhookRequest true "Webhook changes"
+		// @Success 200 {object} dtos.WebhookResponse "Webhook updated"
+		// @Failure 404 {object} map[string]interface{} "Webhook not found"
+		// @Router /webhooks/{id} [patch]
+		webhookRoutes.PATCH("/:id", controllers.UpdateWebhook)
+
+		// @Summary Delete Webhook
+		// @Description Delete a webhook and its delivery log
+		// @Tags Webhooks
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "Webhook ID"
+		// @Success 200 {object} map[string]interface{} "Webhook deleted"
+		// @Failure 404 {object} map[string]interface{} "Webhook not found"
+		// @Router /webhooks/{id} [delete]
+		webhookRoutes.DELETE("/:id", controllers.DeleteWebhook)
+
+		// @Summary List Webhook Deliveries
+		// @Description Retrieve the delivery log of a webhook
+		// @Tags Webhooks
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "Webhook ID"
+		// @Param status query string false "pending, succeeded or failed"
+		// @Success 200 {array} dtos.WebhookDeliveryResponse "Deliveries, newest first"
+		// @Failure 404 {object} map[string]interface{} "Webhook not found"
+		// @Router /webhooks/{id}/deliveries [get]
+		webhookRoutes.GET("/:id/deliveries", controllers.GetWebhookDeliveries)
+
+		// @Summary Redeliver Webhook
+		// @Description Queue a previous payload for delivery again
+		// @Tags Webhooks
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "Webhook ID"
+		// @Param deliveryId path int true "Delivery to resend"
+		// @Success 202 {object} dtos.WebhookDeliveryResponse "Redelivery queued"
+		// @Failure 404 {object} map[string]interface{} "Webhook or delivery not found"
+		// @Failure 409 {object} map[string]interface{} "Webhook is inactive"
+		// @Router /webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+		webhookRoutes.POST("/:id/deliveries/:deliveryId/redeliver", controllers.RedeliverWebhook)
+	}
+
+	// Admin routes
+	admin := v1.Group("/admin",
+		middleware.RequireAuthWithToken,
+		middleware.RequireRole(models.RoleAdmin),
+		middleware.RequireScope(auth.ScopeAdmin),
+	)
+	{
+		// @Summary List Users
+		// @Description List and search all user accounts (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Produce json
+		// @Param q query string false "Search name and email"
+		// @Param role query string false "Only users with this role"
+		// @Param disabled query bool false "Only disabled or active accounts"
+		// @Param page query int false "Page number"
+		// @Param pageSize query int false "Results per page"
+		// @Success 200 {object} dtos.AdminUserListResponse "Users"
+		// @Failure 403 {object} map[string]interface{} "Forbidden - not an admin"
+		// @Router /admin/users [get]
+		admin.GET("/users", controllers.AdminGetUsers)
+
+		// @Summary Get User
+		// @Description Retrieve any user account (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "User ID"
+		// @Success 200 {object} dtos.AdminUserResponse "User"
+		// @Failure 404 {object} map[string]interface{} "User not found"
+		// @Router /admin/users/{id} [get]
+		admin.GET("/users/:id", controllers.AdminGetUser)
+
+		// @Summary Disable User
+		// @Description Disable an account and log it out everywhere (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "User ID"
+		// @Success 200 {object} dtos.AdminUserResponse "User disabled"
+		// @Failure 404 {object} map[string]interface{} "User not found"
+		// @Router /admin/users/{id}/disable [post]
+		admin.POST("/users/:id/disable", controllers.AdminDisableUser)
+
+		// @Summary Enable User
+		// @Description Re-enable a disabled account (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "User ID"
+		// @Success 200 {object} dtos.AdminUserResponse "User enabled"
+		// @Failure 404 {object} map[string]interface{} "User not found"
+		// @Router /admin/users/{id}/enable [post]
+		admin.POST("/users/:id/enable", controllers.AdminEnableUser)
+
+		// @Summary Change User Role
+		// @Description Promote a user to ADMIN or demote them to USER (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param id path int true "User ID"
+		// @Param request body dtos.UpdateUserRoleRequest true "New role"
+		// @Success 200 {object} dtos.AdminUserResponse "Role changed"
+		// @Failure 400 {object} map[string]interface{} "Bad request"
+		// @Router /admin/users/{id}/role [put]
+		admin.PUT("/users/:id/role", controllers.AdminUpdateUserRole)
+
+		// @Summary Unlock User Login
+		// @Description Lift the login lockouts of an account for every IP and forget its failed logins (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "User ID"
+		// @Success 200 {object} dtos.AdminUserResponse "User unlocked"
+		// @Failure 404 {object} map[string]interface{} "User not found"
+		// @Router /admin/users/{id}/unlock [post]
+		admin.POST("/users/:id/unlock", controllers.AdminUnlockUser)
+
+		// @Summary List Login Lockouts
+		// @Description Accounts and IP addresses currently locked out after repeated failed logins (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {object} []dtos.LoginLockoutResponse "Active lockouts"
+		// @Router /admin/login-lockouts [get]
+		admin.GET("/login-lockouts", controllers.AdminGetLoginLockouts)
+
+		// @Summary Clear Login Lockout
+		// @Description Lift the lockout of an account or IP address (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Produce json
+		// @Param key query string true "Lockout key, e.g. account:john@example.com or ip:203.0.113.7"
+		// @Success 200 {object} map[string]interface{} "Lockout cleared"
+		// @Failure 400 {object} map[string]interface{} "Invalid key"
+		// @Router /admin/login-lockouts [delete]
+		admin.DELETE("/login-lockouts", controllers.AdminClearLoginLockout)
+
+		// @Summary List Audit Events
+		// @Description Security events such as failed logins, lockouts and unlocks, newest first (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Produce json
+		// @Param type query string false "Only events of this type"
+		// @Param userId query int false "Only events concerning this user"
+		// @Success 200 {object} dtos.AuditEventListResponse "Events"
+		// @Router /admin/audit-events [get]
+		admin.GET("/audit-events", controllers.AdminGetAuditEvents)
+
+		// @Summary List All Links
+		// @Description List and search the links of all users (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Produce json
+		// @Param q query string false "Search title, URL and short code"
+		// @Param userId query int false "Only links owned by this user"
+		// @Param includeDeleted query bool false "Include links in the trash"
+		// @Success 200 {object} dtos.AdminLinkListResponse "Links"
+		// @Router /admin/links [get]
+		admin.GET("/links", controllers.AdminGetLinks)
+
+		// @Summary Get Any Link
+		// @Description Retrieve any link by ID (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "Link ID"
+		// @Success 200 {object} dtos.AdminLinkResponse "Link"
+		// @Failure 404 {object} map[string]interface{} "Link not found"
+		// @Router /admin/links/{id} [get]
+		admin.GET("/links/:id", controllers.AdminGetLink)
+
+		// @Summary Force-Delete Link
+		// @Description Permanently delete any link (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "Link ID"
+		// @Success 200 {object} map[string]interface{} "Link deleted"
+		// @Failure 404 {object} map[string]interface{} "Link not found"
+		// @Router /admin/links/{id} [delete]
+		admin.DELETE("/links/:id", controllers.AdminDeleteLink)
+
+		// @Summary System Stats
+		// @Description System-wide counts of users, links, clicks and integrations (admins only)
+		// @Tags Admin
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {object} dtos.SystemStatsResponse "Statistics"
+		// @Router /admin/stats [get]
+		admin.GET("/stats", controllers.AdminGetStats)
+	}
+
+	// API key routes
+	apiKeys := v1.Group("/api-keys", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAccount))
+	{
+		// @Summary List API Keys
+		// @Description Retrieve the authenticated user's API keys (prefix, scopes, expiry and last use)
+		// @Tags API Keys
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {array} dtos.APIKeyResponse "User's API keys"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /api-keys [get]
+		apiKeys.GET("", controllers.GetAPIKeys)
+
+		// @Summary Create API Key
+		// @Description Create a scoped API key for scripts and CI. The key is only shown once
+		// @Tags API Keys
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.CreateAPIKeyRequest true "API key details"
+		// @Success 201 {object} dtos.APIKeyResponse "API key created, including the key"
+		// @Failure 400 {object} map[string]interface{} "Bad request"
+		// @Router /api-keys [post]
+		apiKeys.POST("", controllers.CreateAPIKey)
+
+		// @Summary Revoke API Key
+		// @Description Revoke an API key immediately
+		// @Tags API Keys
+		// @Security Bearer
+		// @Produce json
+		// @Param id path int true "API key ID"
+		// @Success 200 {object} map[string]interface{} "API key revoked"
+		// @Failure 404 {object} map[string]interface{} "API key not found"
+		// @Router /api-keys/{id} [delete]
+		apiKeys.DELETE("/:id", controllers.RevokeAPIKey)
+	}
+
+	// Report digest routes
+	reportRoutes := v1.Group("/reports", middleware.RequireAuthWithToken, middleware.RequireScope(auth.ScopeAnalyticsRead))
+	{
+		// @Summary Get Report Settings
+		// @Description Retrieve how often link performance digests are emailed
+		// @Tags Reports
+		// @Security Bearer
+		// @Produce json
+		// @Success 200 {object} dtos.ReportSettingsResponse "Report settings"
+		// @Failure 401 {object} map[string]interface{} "Unauthorized"
+		// @Router /reports/settings [get]
+		reportRoutes.GET("/settings", controllers.GetReportSettings)
+
+		// @Summary Update Report Settings
+		// @Description Receive link performance digests daily, weekly or never
+		// @Tags Reports
+		// @Security Bearer
+		// @Accept json
+		// @Produce json
+		// @Param request body dtos.ReportSettingsRequest true "Report settings"
+		// @Success 200 {object} dtos.ReportSettingsResponse "Report settings updated"
+		// @Failure 400 {object} map[string]interface{} "Bad request"
+		// @Router /reports/settings [put]
+		reportRoutes.PUT("/settings", controllers.UpdateReportSettings)
+
+		// @Summary Preview Report
+		// @Description Build the digest for the latest complete day or week without sending it
+		// @Tags Reports
+		// @Security Bearer
+		// @Produce json
+		// @Param frequency query string false "daily or weekly (default weekly)"
+		// @Success 200 {object} reports.Report "Report"
+		// @Failure 400 {object} map[string]interface{} "Bad request"
+		// @Router /reports/preview [get]
+		reportRoutes.GET("/preview", controllers.PreviewReport)
+
+		// @Summary Send Report Now
+		// @Description Email the digest for the latest complete day or week to yourself
+		// @Tags Reports
+		// @Security Bearer
+		// @Produce json
+		// @Param frequency query string false "daily or weekly (default weekly)"
+		// @Success 200 {object} map[string]interface{} "Report sent"
+		// @Failure 502 {object} map[string]interface{} "Mail server error"
+		// @Router /reports/send [post]
+		reportRoutes.POST("/send", controllers.SendTestReport)
 	}
 
 	// Redirect route - accessible at root level (e.g., localhost:8080/my-link)
 	// IMPORTANT: This should be defined AFTER all other routes to avoid conflicts
 	// @Summary Redirect to Link
-	// @Description Redirect to original URL and increment clicks
+	// @Description Redirect to original URL and increment clicks. The short code is looked up on the domain in the Host header
 	// @Tags Redirect
 	// @Accept json
 	// @Produce json
@@ -183,6 +1043,46 @@ func main() {
 	// @Router /{shortCode} [get]
 	router.GET("/:shortCode", controllers.RedirectLink)
 
+	// @Summary Preview Link
+	// @Description Show where a short link goes without redirecting or counting a click
+	// @Tags Redirect
+	// @Produce json
+	// @Param shortCode path string true "Short code of the link"
+	// @Success 200 {object} dtos.LinkPreviewResponse "Link preview"
+	// @Failure 404 {object} map[string]interface{} "Link not found"
+	// @Router /{shortCode}/preview [get]
+	router.GET("/:shortCode/preview", controllers.PreviewLink)
+
+	// @Summary Link QR Code
+	// @Description Render the short URL as a QR code PNG
+	// @Tags Redirect
+	// @Produce png
+	// @Param shortCode path string true "Short code of the link"
+	// @Param scale query int false "Pixels per QR module (1-20, default 8)"
+	// @Success 200 {file} binary "QR code image"
+	// @Failure 404 {object} map[string]interface{} "Link not found"
+	// @Router /{shortCode}/qr [get]
+	router.GET("/:shortCode/qr", controllers.GetLinkQRCode)
+
+	// @Summary Public Link Stats
+	// @Description Read-only statistics of a link whose owner shared them, as HTML or JSON (?format=json)
+	// @Tags Public Stats
+	// @Produce html
+	// @Produce json
+	// @Param token path string true "Share token"
+	// @Success 200 {object} dtos.PublicLinkStatsResponse "Link statistics"
+	// @Failure 404 {object} map[string]interface{} "Stats not found"
+	// @Router /s/{token} [get]
+	router.GET("/s/:token", controllers.GetPublicLinkStats)
+
+	// Background jobs
+	jobs.StartTrashPurger()
+	jobs.StartLinkHealthChecker()
+	jobs.StartWebhookDispatcher()
+	jobs.StartVisitorPruner()
+	jobs.StartReportDigests()
+	jobs.StartTokenCleanup()
+
 	// Start server
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"strings"
@@ -9,13 +10,21 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/olujimiAdebakin/Shurl/auth"
 	"github.com/olujimiAdebakin/Shurl/controllers"
 	"github.com/olujimiAdebakin/Shurl/dtos"
 	"github.com/olujimiAdebakin/Shurl/initializers"
 	"github.com/olujimiAdebakin/Shurl/models"
 )
 
+// RequireAuthWithToken authenticates the request with a Bearer access token or a personal
+// API key, sent as "Authorization: ApiKey <key>" or in the X-API-Key header.
 func RequireAuthWithToken(c *gin.Context) {
+	if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+		authenticateAPIKey(c, apiKey)
+		return
+	}
+
 	// Get token from Authorization header
 	authHeader := c.GetHeader("Authorization")
 
@@ -28,18 +37,46 @@ func RequireAuthWithToken(c *gin.Context) {
 		return
 	}
 
+	if apiKey := strings.TrimPrefix(authHeader, "ApiKey "); apiKey != authHeader {
+		authenticateAPIKey(c, apiKey)
+		return
+	}
+
 	// Extract token from "Bearer <token>" format
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
 	if tokenString == authHeader {
 		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
 			Success: false,
-			Error:   "Unauthorized - Invalid token format. Use 'Bearer <token>'",
+			Error:   "Unauthorized - Invalid token format. Use 'Bearer <token>' or 'ApiKey <key>'",
 		})
 		c.Abort()
 		return
 	}
 
+	authenticateJWT(c, tokenString)
+}
+
+func RequireAuthWithCookie(c *gin.Context) {
+	// Get cookie from request
+	tokenString, err := c.Cookie("Authorization")
+
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized - No token provided",
+		})
+		c.Abort()
+		return
+	}
+
+	authenticateJWT(c, tokenString)
+}
+
+// authenticateJWT validates an access token and attaches its user to the context.
+// Besides the signature and expiry it rejects logged-out tokens and tokens issued
+// before the user last logged out of all sessions.
+func authenticateJWT(c *gin.Context, tokenString string) {
 	// Decode/Validate the token
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -58,123 +95,185 @@ func RequireAuthWithToken(c *gin.Context) {
 	}
 
 	// Extract claims and validate
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		// Check expiration
-		if float64(time.Now().Unix()) > claims["exp"].(float64) {
-			c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized - Invalid token claims",
+		})
+		c.Abort()
+		return
+	}
+
+	// Check expiration
+	exp, ok := claims["exp"].(float64)
+	if !ok || float64(time.Now().Unix()) > exp {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized - Token expired",
+		})
+		c.Abort()
+		return
+	}
+
+	// Check the token was not logged out
+	jti, _ := claims["jti"].(string)
+	if jti != "" {
+		revoked, err := auth.IsTokenRevoked(jti)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
 				Success: false,
-				Error:   "Unauthorized - Token expired",
+				Error:   "Failed to check token",
 			})
 			c.Abort()
 			return
 		}
-
-		// Find user with token sub
-		var user models.User
-		initializers.DB.First(&user, claims["sub"])
-
-		if user.ID == 0 {
+		if revoked {
 			c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
 				Success: false,
-				Error:   "Unauthorized - User not found",
+				Error:   "Unauthorized - Token revoked",
 			})
 			c.Abort()
 			return
 		}
+	}
 
-		// Convert user model to context struct
-		contextUser := controllers.ContextUserStruct{
-			ID:    user.ID,
-			Email: user.Email,
-			Name:  user.Name,
-			Role:  user.Role,
-		}
+	// Find user with token sub
+	var user models.User
+	initializers.DB.First(&user, claims["sub"])
 
-		// Attach user to context
-		c.Set("user", contextUser)
+	if user.ID == 0 {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized - User not found",
+		})
+		c.Abort()
+		return
+	}
 
-		// Continue to next handler
-		c.Next()
-	} else {
+	if !requireEnabledUser(c, user) {
+		return
+	}
+
+	// Tokens issued before "log out all sessions" are no longer valid. Compared in microseconds, the
+	// precision of both iat and the database, so a token from the same second is not let through
+	iat, _ := claims["iat"].(float64)
+	if user.TokensValidAfter != nil && int64(math.Round(iat*1e6)) < user.TokensValidAfter.UnixMicro() {
 		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
 			Success: false,
-			Error:   "Unauthorized - Invalid token claims",
+			Error:   "Unauthorized - Token revoked",
 		})
 		c.Abort()
 		return
 	}
+
+	// Convert user model to context struct
+	contextUser := controllers.ContextUserStruct{
+		ID:            user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerifiedAt != nil,
+	}
+
+	// Attach user and token details to context
+	c.Set("user", contextUser)
+	sessionID, _ := claims["sid"].(string)
+	contextToken := controllers.ContextTokenStruct{
+		ID:        jti,
+		SessionID: sessionID,
+		ExpiresAt: time.Unix(int64(exp), 0),
+	}
+	// Tokens issued before scopes were added carry none; they get what a login grants by default,
+	// never more, so the admin scope still depends on the user's current role
+	if scope, ok := claims["scope"].(string); ok {
+		contextToken.Scopes = strings.Fields(scope)
+	} else {
+		contextToken.Scopes = auth.DefaultScopes(user.Role)
+	}
+	c.Set("token", contextToken)
+
+	// Continue to next handler
+	c.Next()
 }
 
-func RequireAuthWithCookie(c *gin.Context) {
-	// Get cookie from request
-	tokenString, err := c.Cookie("Authorization")
+// apiKeyUsageInterval limits how often an API key's last-used time is written.
+const apiKeyUsageInterval = time.Minute
 
-	if err != nil {
+// authenticateAPIKey looks up a personal API key and attaches its owner to the context.
+func authenticateAPIKey(c *gin.Context, key string) {
+	var apiKey models.APIKey
+	result := initializers.DB.Where("key_hash = ?", auth.HashAPIKey(strings.TrimSpace(key))).Limit(1).Find(&apiKey)
+
+	if result.Error != nil || result.RowsAffected == 0 {
 		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
 			Success: false,
-			Error:   "Unauthorized - No token provided",
+			Error:   "Unauthorized - Invalid API key",
 		})
 		c.Abort()
 		return
 	}
 
-	// Decode/Validate token (same as above)
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(os.Getenv("SECRET_KEY")), nil
-	})
+	now := time.Now()
+	if apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized - API key expired",
+		})
+		c.Abort()
+		return
+	}
 
-	if err != nil {
+	var user models.User
+	initializers.DB.First(&user, apiKey.UserID)
+
+	if user.ID == 0 {
 		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
 			Success: false,
-			Error:   "Unauthorized - Invalid token",
+			Error:   "Unauthorized - User not found",
 		})
 		c.Abort()
 		return
 	}
 
-	// Rest of validation (same as token-based)
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		if float64(time.Now().Unix()) > claims["exp"].(float64) {
-			c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
-				Success: false,
-				Error:   "Unauthorized - Token expired",
-			})
-			c.Abort()
-			return
-		}
+	if !requireEnabledUser(c, user) {
+		return
+	}
 
-		var user models.User
-		initializers.DB.First(&user, claims["sub"])
+	// Record usage without slowing the request down, at most once a minute per key
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyUsageInterval {
+		go initializers.DB.Model(&models.APIKey{}).Where("id = ?", apiKey.ID).UpdateColumn("last_used_at", now)
+	}
 
-		if user.ID == 0 {
-			c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
-				Success: false,
-				Error:   "Unauthorized - User not found",
-			})
-			c.Abort()
-			return
-		}
+	c.Set("user", controllers.ContextUserStruct{
+		ID:            user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerifiedAt != nil,
+	})
 
-		// Convert user model to context struct
-		contextUser := controllers.ContextUserStruct{
-			ID:    user.ID,
-			Email: user.Email,
-			Name:  user.Name,
-			Role:  user.Role,
-		}
+	contextToken := controllers.ContextTokenStruct{
+		APIKeyID: apiKey.ID,
+		Scopes:   auth.SplitScopes(apiKey.Scopes),
+	}
+	if apiKey.ExpiresAt != nil {
+		contextToken.ExpiresAt = *apiKey.ExpiresAt
+	}
+	c.Set("token", contextToken)
 
-		c.Set("user", contextUser)
-		c.Next()
-	} else {
-		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+	c.Next()
+}
+
+// requireEnabledUser rejects requests from accounts an admin has disabled.
+func requireEnabledUser(c *gin.Context, user models.User) bool {
+	if user.DisabledAt != nil {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
 			Success: false,
-			Error:   "Unauthorized - Invalid token claims",
+			Error:   "Forbidden - Account disabled",
 		})
 		c.Abort()
-		return
+		return false
 	}
+	return true
 }
-
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/controllers"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+)
+
+// RequireRole rejects requests from users whose role is not one of roles.
+// It must run after RequireAuthWithToken or RequireAuthWithCookie.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("user")
+		contextUser, ok := value.(controllers.ContextUserStruct)
+		if !exists || !ok {
+			c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Unauthorized",
+			})
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if contextUser.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Forbidden - Requires role " + strings.Join(roles, " or "),
+		})
+		c.Abort()
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/controllers"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+)
+
+// RequireScope rejects requests whose access token or API key lacks any of the given scopes.
+// It must run after RequireAuthWithToken or RequireAuthWithCookie.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("token")
+		contextToken, ok := value.(controllers.ContextTokenStruct)
+		if !exists || !ok {
+			c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Unauthorized - No token provided",
+			})
+			c.Abort()
+			return
+		}
+
+		for _, scope := range scopes {
+			if !contextToken.HasScope(scope) {
+				c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+					Success: false,
+					Error:   "Forbidden - Missing scope " + scope,
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
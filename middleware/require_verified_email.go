@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olujimiAdebakin/Shurl/controllers"
+	"github.com/olujimiAdebakin/Shurl/dtos"
+	"github.com/olujimiAdebakin/Shurl/initializers"
+)
+
+// RequireVerifiedEmail rejects users who have not verified their email address yet,
+// when REQUIRE_EMAIL_VERIFICATION is enabled. It must run after RequireAuthWithToken.
+func RequireVerifiedEmail(c *gin.Context) {
+	if !initializers.EmailVerificationRequired() {
+		c.Next()
+		return
+	}
+
+	value, _ := c.Get("user")
+	if contextUser, ok := value.(controllers.ContextUserStruct); !ok || !contextUser.EmailVerified {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Forbidden - Verify your email address first",
+		})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
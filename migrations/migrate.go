@@ -5,6 +5,7 @@ import (
 
 	"github.com/olujimiAdebakin/Shurl/initializers"
 	"github.com/olujimiAdebakin/Shurl/models"
+	"gorm.io/gorm"
 )
 
 func init() {
@@ -15,10 +16,35 @@ func init() {
 func main() {
 	log.Println("Starting database migration...")
 
+	// Accounts created before email verification existed are trusted as verified, see below
+	backfillEmailVerified := initializers.DB.Migrator().HasTable(&models.User{}) &&
+		!initializers.DB.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+
 	// Run migrations for all models
 	err := initializers.DB.AutoMigrate(
 		&models.User{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.APIKey{},
+		&models.PasswordResetToken{},
+		&models.RecoveryCode{},
+		&models.LoginAttempt{},
+		&models.AuditEvent{},
 		&models.Link{},
+		&models.LinkRevision{},
+		&models.LinkAlias{},
+		&models.VisitorSalt{},
+		&models.LinkVisitorDay{},
+		&models.LinkVisitor{},
+		&models.LinkBotHit{},
+		&models.LinkClickDay{},
+		&models.LinkReferrer{},
+		&models.LinkCountry{},
+		&models.Domain{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.Tag{},
+		&models.Folder{},
 		// &models.Supplier{},
 		// &models.Farmer{},
 	)
@@ -27,5 +53,42 @@ func main() {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	// Without this, every existing account would be locked out of REQUIRE_EMAIL_VERIFICATION routes and digests
+	if backfillEmailVerified {
+		if err := initializers.DB.Model(&models.User{}).
+			Where("email_verified_at IS NULL").
+			UpdateColumn("email_verified_at", gorm.Expr("created_at")).Error; err != nil {
+			log.Fatal("Failed to mark existing users as verified:", err)
+		}
+	}
+
+	// Short codes used to be globally unique; they are now unique per domain
+	migrator := initializers.DB.Migrator()
+	if migrator.HasIndex(&models.Link{}, "idx_links_short_code") {
+		if err := migrator.DropIndex(&models.Link{}, "idx_links_short_code"); err != nil {
+			log.Fatal("Failed to drop global short code index:", err)
+		}
+	}
+	if migrator.HasIndex(&models.LinkAlias{}, "idx_link_aliases_short_code") {
+		if err := migrator.DropIndex(&models.LinkAlias{}, "idx_link_aliases_short_code"); err != nil {
+			log.Fatal("Failed to drop global alias index:", err)
+		}
+	}
+
+	// Destination hashes used to be unique, which kept trashed links and other users from sharing a URL
+	if indexes, err := migrator.GetIndexes(&models.Link{}); err == nil {
+		for _, index := range indexes {
+			if unique, _ := index.Unique(); index.Name() != "idx_links_hash" || !unique {
+				continue
+			}
+			if err := migrator.DropIndex(&models.Link{}, "idx_links_hash"); err != nil {
+				log.Fatal("Failed to drop unique hash index:", err)
+			}
+			if err := migrator.CreateIndex(&models.Link{}, "Hash"); err != nil {
+				log.Fatal("Failed to create hash index:", err)
+			}
+		}
+	}
+
 	log.Println("✅ Database migration completed successfully!")
 }
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// @title APIKey Struct
+// @notice A personal API key for scripts and CI, acting as its owner with limited scopes.
+type APIKey struct {
+	// @dev gorm.Model is embedded to provide standard ID, CreatedAt, UpdatedAt, and DeletedAt fields.
+	// Revoked keys are soft-deleted so they stay visible in audits but can no longer authenticate.
+	gorm.Model
+
+	UserID uint `gorm:"index;NOT NULL"`
+
+	// @notice A label to tell keys apart, e.g. "GitHub Actions".
+	Name string `gorm:"NOT NULL"`
+
+	// @notice The first characters of the key, shown in listings.
+	Prefix string `gorm:"NOT NULL"`
+
+	// @notice SHA-256 of the key. The key itself is only shown once, when it is created.
+	KeyHash string `gorm:"uniqueIndex;NOT NULL"`
+
+	// @notice Comma separated list of granted scopes.
+	Scopes string `gorm:"NOT NULL"`
+
+	// @notice When the key stops working, nil if it never expires.
+	ExpiresAt *time.Time
+
+	// @notice When the key was last used, nil if never.
+	LastUsedAt *time.Time
+}
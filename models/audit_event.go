@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// @title AuditEvent Struct
+// @notice A security-relevant event, such as a failed login or an account lockout, for admins to review.
+// @dev Rows are append-only and deleted after AUDIT_RETENTION_DAYS.
+type AuditEvent struct {
+	ID uint `gorm:"primaryKey"`
+
+	// @notice Event type, e.g. login.failed.
+	Type string `gorm:"index;NOT NULL"`
+
+	// @notice The account the event concerns, nil when unknown (e.g. a login with an unregistered email).
+	UserID *uint `gorm:"index"`
+
+	// @notice The email the request used, kept even when it matches no account.
+	Email string `gorm:"index"`
+
+	// @notice IP address of the client that caused the event.
+	IP string
+
+	// @notice Who acted, for events triggered by an admin.
+	ActorID *uint
+
+	// @notice Free-form details, e.g. how long an account was locked for.
+	Detail string
+
+	CreatedAt time.Time `gorm:"index"`
+}
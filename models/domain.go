@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// @title Domain Struct
+// @notice A custom branded hostname (e.g. go.acme.com) a user can create links on.
+// Links on a domain have their own short code namespace, separate from the default domain.
+type Domain struct {
+	// @dev gorm.Model is embedded to provide standard ID, CreatedAt, UpdatedAt, and DeletedAt fields.
+	// Domains are always hard-deleted so the hostname can be claimed again.
+	gorm.Model
+
+	// @notice The owner of the domain.
+	UserID uint `gorm:"index;NOT NULL"`
+
+	// @notice The lowercase hostname, without scheme or port.
+	// @dev Only unique among verified domains, so an unverified claim cannot block the real owner.
+	// Several users may register the same hostname; the first to verify it gets it.
+	Hostname string `gorm:"index;uniqueIndex:idx_domains_verified_hostname,where:verified_at IS NOT NULL;NOT NULL"`
+
+	// @notice Random token the owner publishes via DNS TXT or HTTP to prove control of the hostname.
+	VerificationToken string `gorm:"NOT NULL"`
+
+	// @notice When ownership was verified. Links can only be created on verified domains.
+	VerifiedAt *time.Time
+}
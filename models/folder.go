@@ -0,0 +1,23 @@
+package models
+
+import "gorm.io/gorm"
+
+// @title Folder Struct
+// @notice A per-user collection of links. Folders can be nested via ParentID.
+type Folder struct {
+	// @dev gorm.Model is embedded to provide standard ID, CreatedAt, UpdatedAt, and DeletedAt fields.
+	// Folders are always hard-deleted.
+	gorm.Model
+
+	// @notice The owner of the folder.
+	UserID uint `gorm:"index;NOT NULL"`
+
+	// @notice The display name of the folder.
+	Name string `gorm:"NOT NULL"`
+
+	// @notice The enclosing folder, or nil for a top-level folder.
+	ParentID *uint `gorm:"index"`
+
+	Children []Folder `gorm:"foreignKey:ParentID;constraint:OnDelete:SET NULL"`
+	Links    []Link   `gorm:"constraint:OnDelete:SET NULL"`
+}
@@ -1,6 +1,17 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Link health statuses recorded by the background checker
+const (
+	HealthUnknown = "unknown"
+	HealthOK      = "ok"
+	HealthBroken  = "broken"
+)
 
 
 type Link struct{
@@ -8,10 +19,62 @@ type Link struct{
 	// @dev gorm.Model is embedded to provide standard ID, CreatedAt, UpdatedAt, and DeletedAt fields.
 	gorm.Model
 
-	ShortCode string `gorm:"uniqueIndex"`
+	// @dev Short codes are unique per domain; DomainID 0 is the default domain.
+	ShortCode string `gorm:"uniqueIndex:idx_links_domain_code"`
+	DomainID uint `gorm:"uniqueIndex:idx_links_domain_code;default:0;NOT NULL"`
 	OriginalURL string `gorm:"NOT NULL"`
-	Hash string `gorm:"uniqueIndex;NOT NULL"`
+	// @dev Not unique: trashed links keep their row, and several users or domains may link to the same URL.
+	Hash string `gorm:"index;NOT NULL"`
 	Clicks int `gorm:"default:0"`
+	// @notice Distinct visitors, each counted at most once per day. Refreshes do not add to it.
+	UniqueClicks int `gorm:"default:0;NOT NULL"`
+	// @notice Redirects made by bots, crawlers and monitors, which are not counted as clicks.
+	BotClicks int `gorm:"default:0;NOT NULL"`
 	Favicon *string 
 	UserID uint `gorm:"default:0"`
+
+	// @notice Human-readable label for the link. Filled from the destination page's <title> when left blank.
+	Title string
+
+	// @notice Optional longer description of the link.
+	Description string `gorm:"type:text"`
+
+	// @notice Private notes visible only to the owner.
+	Notes string `gorm:"type:text"`
+
+	// @notice Result of the last destination health check: unknown, ok or broken.
+	HealthStatus string `gorm:"default:unknown;NOT NULL;index"`
+	// @notice HTTP status returned by the destination, 0 if the request failed.
+	HealthStatusCode int
+	// @notice How long the destination took to respond, in milliseconds.
+	HealthLatencyMs int64
+	// @notice Why the last check failed (timeout, connection error, ...), empty when healthy.
+	HealthError string
+	// @notice When the destination was last checked, nil if never.
+	HealthCheckedAt *time.Time `gorm:"index"`
+
+	// @notice Unguessable token of the public stats page at /s/{token}, nil while the stats are private.
+	StatsShareToken *string `gorm:"uniqueIndex"`
+
+	// @notice The folder the link is filed under, or nil if unfiled.
+	FolderID *uint `gorm:"index"`
+
+	Tags []Tag `gorm:"many2many:link_tags;constraint:OnDelete:CASCADE"`
+
+	// @dev Loaded for links on custom domains; nil for the default domain, hence no FK constraint.
+	Domain *Domain `gorm:"constraint:-"`
+
+	// @dev Aliases are removed with the link when it is permanently deleted.
+	Aliases []LinkAlias `gorm:"constraint:OnDelete:CASCADE"`
+
+	// @dev Revisions are removed with the link when it is permanently deleted.
+	Revisions []LinkRevision `gorm:"constraint:OnDelete:CASCADE"`
+
+	// @dev Analytics rows (visitors, bot hits, click rollups) are removed with the link when it is permanently deleted.
+	VisitorDays []LinkVisitorDay `gorm:"constraint:OnDelete:CASCADE"`
+	Visitors []LinkVisitor `gorm:"constraint:OnDelete:CASCADE"`
+	BotHits []LinkBotHit `gorm:"constraint:OnDelete:CASCADE"`
+	ClickDays []LinkClickDay `gorm:"constraint:OnDelete:CASCADE"`
+	Referrers []LinkReferrer `gorm:"constraint:OnDelete:CASCADE"`
+	Countries []LinkCountry `gorm:"constraint:OnDelete:CASCADE"`
 }
\ No newline at end of file
@@ -0,0 +1,21 @@
+package models
+
+import "gorm.io/gorm"
+
+// @title LinkAlias Struct
+// @notice An additional short code that redirects to the same link as its primary code.
+// All aliases share the link's destination and click counts.
+type LinkAlias struct {
+	// @dev gorm.Model is embedded to provide standard ID, CreatedAt, UpdatedAt, and DeletedAt fields.
+	// Aliases are always hard-deleted so their short codes can be reused.
+	gorm.Model
+
+	// @notice The alternative short code. Must not clash with any primary code on the same domain.
+	ShortCode string `gorm:"uniqueIndex:idx_link_aliases_domain_code;NOT NULL"`
+
+	// @notice The domain of the aliased link, copied so codes can be unique per domain.
+	DomainID uint `gorm:"uniqueIndex:idx_link_aliases_domain_code;default:0;NOT NULL"`
+
+	// @notice The link this alias redirects to.
+	LinkID uint `gorm:"index;NOT NULL"`
+}
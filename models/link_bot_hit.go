@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// @title LinkBotHit Struct
+// @notice Counts redirects of a link made by one kind of bot (link unfurler, crawler, monitor, ...).
+// Bot hits are kept out of the link's click counts.
+type LinkBotHit struct {
+	LinkID uint `gorm:"primaryKey;autoIncrement:false"`
+
+	// @notice The bot's name from the rules file, e.g. Slack or Googlebot.
+	Name string `gorm:"primaryKey"`
+
+	// @notice unfurler, crawler, monitor, tool or other.
+	Category string `gorm:"NOT NULL"`
+
+	Hits int `gorm:"default:0;NOT NULL"`
+
+	LastSeenAt time.Time
+}
@@ -0,0 +1,34 @@
+package models
+
+// @title LinkClickDay Struct
+// @notice Number of clicks a link received on one UTC day, used for timelines.
+type LinkClickDay struct {
+	LinkID uint `gorm:"primaryKey;autoIncrement:false"`
+
+	// @notice The UTC day, formatted as 2006-01-02.
+	Day string `gorm:"primaryKey"`
+
+	Clicks int `gorm:"default:0;NOT NULL"`
+}
+
+// @title LinkReferrer Struct
+// @notice Number of clicks a link received from one referring site.
+type LinkReferrer struct {
+	LinkID uint `gorm:"primaryKey;autoIncrement:false"`
+
+	// @notice The referring hostname, empty for direct visits.
+	Referrer string `gorm:"primaryKey"`
+
+	Clicks int `gorm:"default:0;NOT NULL"`
+}
+
+// @title LinkCountry Struct
+// @notice Number of clicks a link received from one country.
+type LinkCountry struct {
+	LinkID uint `gorm:"primaryKey;autoIncrement:false"`
+
+	// @notice ISO 3166-1 alpha-2 country code, empty when unknown.
+	Country string `gorm:"primaryKey"`
+
+	Clicks int `gorm:"default:0;NOT NULL"`
+}
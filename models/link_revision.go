@@ -0,0 +1,21 @@
+package models
+
+import "gorm.io/gorm"
+
+// @title LinkRevision Struct
+// @notice Records a previous destination of a link each time it is changed.
+// Rows are append-only and form the audit trail returned by the history endpoint.
+type LinkRevision struct {
+	// @dev gorm.Model is embedded to provide standard ID, CreatedAt, UpdatedAt, and DeletedAt fields.
+	// CreatedAt is the moment the destination was replaced.
+	gorm.Model
+
+	// @notice The link this revision belongs to.
+	LinkID uint `gorm:"index;NOT NULL"`
+
+	// @notice The destination the link pointed to before the change.
+	OriginalURL string `gorm:"NOT NULL"`
+
+	// @notice The user who made the change.
+	ChangedByID uint `gorm:"NOT NULL"`
+}
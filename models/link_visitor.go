@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// @title VisitorSalt Struct
+// @notice The random salt visitor hashes are computed with on a given day.
+// @dev Salts are deleted once their day is over, after which a hash can no longer be
+// linked to an IP address and user agent, or to the same visitor on another day.
+type VisitorSalt struct {
+	// @notice The UTC day the salt is used for, formatted as 2006-01-02.
+	Day string `gorm:"primaryKey"`
+
+	Salt string `gorm:"NOT NULL"`
+
+	CreatedAt time.Time
+}
+
+// @title LinkVisitorDay Struct
+// @notice Tracks the distinct visitors of a link on one UTC day.
+// Visitors are counted exactly while there are few of them, then with a HyperLogLog sketch.
+type LinkVisitorDay struct {
+	LinkID uint `gorm:"primaryKey;autoIncrement:false"`
+
+	// @notice The UTC day, formatted as 2006-01-02.
+	Day string `gorm:"primaryKey"`
+
+	// @notice How many unique visitors have been added to the link's total for this day.
+	Counted int `gorm:"default:0;NOT NULL"`
+
+	// @notice HyperLogLog registers, nil while visitors are still counted exactly.
+	Sketch []byte
+}
+
+// @title LinkVisitor Struct
+// @notice A visitor hash seen on a link on one UTC day, kept only while the day is being counted exactly.
+type LinkVisitor struct {
+	LinkID uint `gorm:"primaryKey;autoIncrement:false"`
+
+	Day string `gorm:"primaryKey"`
+
+	// @notice Hash of the daily salt, IP address and user agent.
+	VisitorHash string `gorm:"primaryKey"`
+}
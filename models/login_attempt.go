@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// @title LoginAttempt Struct
+// @notice Failed login counter for an account or an IP address, used by the database login guard store.
+// @dev Rows are deleted once the failures are older than the counting window and no lockout is active.
+type LoginAttempt struct {
+	// @notice "account:<email>" or "ip:<address>".
+	Key string `gorm:"primaryKey"`
+
+	// @notice Failed attempts since the counter was last reset.
+	Failures int `gorm:"default:0;NOT NULL"`
+
+	LastFailureAt time.Time `gorm:"index;NOT NULL"`
+
+	// @notice Logins are refused until this time, nil when not locked.
+	LockedUntil *time.Time `gorm:"index"`
+}
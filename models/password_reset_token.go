@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// @title PasswordResetToken Struct
+// @notice A pending "forgot password" request. The token is emailed to the user and can be used once.
+// @dev Rows are deleted once expired.
+type PasswordResetToken struct {
+	ID uint `gorm:"primaryKey"`
+
+	UserID uint `gorm:"index;NOT NULL"`
+
+	// @notice SHA-256 of the token. The token itself is only ever in the email.
+	TokenHash string `gorm:"uniqueIndex;NOT NULL"`
+
+	ExpiresAt time.Time `gorm:"index;NOT NULL"`
+
+	// @notice When the token was used, or superseded by a newer request; nil while it is usable.
+	UsedAt *time.Time
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// @title RecoveryCode Struct
+// @notice A single-use code that replaces a TOTP code when the user has lost their authenticator.
+// @dev A new set replaces the old one whenever codes are (re)generated.
+type RecoveryCode struct {
+	ID uint `gorm:"primaryKey"`
+
+	UserID uint `gorm:"index;NOT NULL"`
+
+	// @notice SHA-256 of the normalized code. The code itself is only shown once.
+	CodeHash string `gorm:"index;NOT NULL"`
+
+	// @notice When the code was used; nil while it is usable.
+	UsedAt *time.Time
+
+	CreatedAt time.Time
+}
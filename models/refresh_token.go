@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// @title RefreshToken Struct
+// @notice A server-side record of a refresh token handed to a client.
+// Each refresh rotates the token: the old one is marked used and a new one is issued in the same family.
+type RefreshToken struct {
+	// @dev gorm.Model is embedded to provide standard ID, CreatedAt, UpdatedAt, and DeletedAt fields.
+	// Refresh tokens are hard-deleted once expired.
+	gorm.Model
+
+	UserID uint `gorm:"index;NOT NULL"`
+
+	// @notice Identifies the chain of tokens descended from one login.
+	// @dev Presenting a used or revoked token revokes the whole family, since the token was stolen or replayed.
+	FamilyID string `gorm:"index;NOT NULL"`
+
+	// @notice SHA-256 of the token. The token itself is only ever known to the client.
+	TokenHash string `gorm:"uniqueIndex;NOT NULL"`
+
+	// @notice Comma separated scopes the login was restricted to, empty for a full session.
+	// @dev Carried over to every token of the family on refresh.
+	Scopes string
+
+	ExpiresAt time.Time `gorm:"index;NOT NULL"`
+
+	// @notice When the token was exchanged for a new one, nil while it is still current.
+	UsedAt *time.Time
+
+	// @notice When the token's family was revoked, nil if it was not.
+	RevokedAt *time.Time
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// @title RevokedToken Struct
+// @notice An access token that was logged out before it expired.
+// @dev Rows are deleted once the token would have expired anyway.
+type RevokedToken struct {
+	// @notice The token's jti claim.
+	JTI string `gorm:"primaryKey"`
+
+	UserID uint `gorm:"index;NOT NULL"`
+
+	// @notice When the token expires; the row is no longer needed after this.
+	ExpiresAt time.Time `gorm:"index;NOT NULL"`
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+import "gorm.io/gorm"
+
+// @title Tag Struct
+// @notice A user-defined label that can be attached to any number of links.
+type Tag struct {
+	// @dev gorm.Model is embedded to provide standard ID, CreatedAt, UpdatedAt, and DeletedAt fields.
+	// Tags are always hard-deleted so their names can be reused.
+	gorm.Model
+
+	// @notice The owner of the tag. Tag names are unique per user.
+	UserID uint `gorm:"uniqueIndex:idx_tags_user_name;NOT NULL"`
+
+	// @notice The display name of the tag.
+	Name string `gorm:"uniqueIndex:idx_tags_user_name;NOT NULL"`
+}
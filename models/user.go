@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 
 // Define role constants
@@ -9,6 +13,13 @@ const (
 	RoleAdmin = "ADMIN"
 )
 
+// Report digest schedules
+const (
+	ReportNever  = "never"
+	ReportDaily  = "daily"
+	ReportWeekly = "weekly"
+)
+
 // @title User Struct
 // @notice Represents a user account in the application.
 // This structure maps directly to the 'users' table in the database and is used by GORM.
@@ -31,5 +42,43 @@ type User struct {
 	// @custom:gorm:default sets the initial value; NOT NULL ensures it is always present.
 	Role string `gorm:"default:USER; NOT NULL"`
 
+	// @notice When the user proved they own the email address, nil until then.
+	EmailVerifiedAt *time.Time
+
+	// @notice When the last verification email was sent, used to throttle resends.
+	EmailVerificationSentAt *time.Time
+
+	// @notice How often a link performance digest is emailed: never, daily or weekly.
+	ReportFrequency string `gorm:"default:never;NOT NULL"`
+
+	// @notice When the last digest was sent, nil if never.
+	// @dev Also used to claim a digest so it is sent once even with several instances running.
+	ReportLastSentAt *time.Time
+
+	// @notice Access tokens issued before this time are rejected. Set by "log out all sessions".
+	TokensValidAfter *time.Time
+
+	// @notice When an admin disabled the account, nil while it is active.
+	// @dev Disabled users cannot log in, refresh tokens or use API keys.
+	DisabledAt *time.Time `gorm:"index"`
+
+	// @notice The TOTP secret, encrypted with SECRET_KEY. Set at enrolment, before 2FA is confirmed.
+	TOTPSecret string
+
+	// @notice When two-factor authentication was confirmed, nil while it is off.
+	TOTPEnabledAt *time.Time
+
+	// @notice The time step of the last accepted code, so a code cannot be used twice.
+	TOTPLastStep int64 `gorm:"default:0;NOT NULL"`
+
+	// @notice Invalid codes entered since the last password login, bounding guesses per login challenge.
+	TOTPFailedAttempts int `gorm:"default:0;NOT NULL"`
+
+	// @notice SHA-256 of the nonce in the outstanding login challenge, empty when there is none.
+	// @dev Cleared when the challenge is used, so each challenge token completes at most one login.
+	TOTPChallengeHash string
+
+	// @dev No cascade: when the account is deleted its links are deleted or anonymized explicitly,
+	// depending on ACCOUNT_DELETION_POLICY.
 	Links []Link
 }
\ No newline at end of file
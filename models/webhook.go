@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// @title Webhook Struct
+// @notice An endpoint a user registered to receive signed event notifications.
+type Webhook struct {
+	// @dev gorm.Model is embedded to provide standard ID, CreatedAt, UpdatedAt, and DeletedAt fields.
+	// Webhooks are always hard-deleted together with their delivery log.
+	gorm.Model
+
+	// @notice The owner of the webhook. Only events for the owner's links are sent.
+	UserID uint `gorm:"index;NOT NULL"`
+
+	// @notice The endpoint payloads are POSTed to.
+	URL string `gorm:"NOT NULL"`
+
+	// @notice Shared secret used to sign payloads with HMAC-SHA256.
+	// @dev Stored in plain text because it is needed to compute signatures.
+	Secret string `gorm:"NOT NULL"`
+
+	// @notice Comma separated list of subscribed event types.
+	Events string `gorm:"NOT NULL"`
+
+	// @notice Inactive webhooks receive no new deliveries.
+	Active bool `gorm:"default:true;NOT NULL"`
+
+	Deliveries []WebhookDelivery `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// @title WebhookDelivery Struct
+// @notice One attempt-tracked delivery of an event to a webhook. Pending rows form the
+// persistent delivery queue; finished rows form the delivery log.
+type WebhookDelivery struct {
+	// @dev gorm.Model is embedded to provide standard ID, CreatedAt, UpdatedAt, and DeletedAt fields.
+	gorm.Model
+
+	WebhookID uint `gorm:"index;NOT NULL"`
+	Webhook   Webhook
+
+	// @notice The event type, e.g. link.created.
+	Event string `gorm:"NOT NULL"`
+
+	// @notice The exact JSON body sent (and signed).
+	Payload string `gorm:"type:text;NOT NULL"`
+
+	// @notice pending, succeeded or failed.
+	Status string `gorm:"default:pending;NOT NULL;index:idx_webhook_deliveries_queue,priority:1"`
+
+	// @notice How many times delivery has been attempted.
+	Attempts int `gorm:"default:0;NOT NULL"`
+
+	// @notice When the next attempt is due. Also used as a lease while a worker is sending.
+	NextAttemptAt time.Time `gorm:"index:idx_webhook_deliveries_queue,priority:2"`
+
+	// @notice HTTP status of the last attempt, 0 if the request failed.
+	LastStatusCode int
+
+	// @notice Error from the last attempt, empty on success.
+	LastError string
+
+	// @notice When the endpoint accepted the delivery.
+	DeliveredAt *time.Time
+}
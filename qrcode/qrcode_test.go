@@ -0,0 +1,172 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const otpauthURL = "otpauth://totp/Shurl:john.doe@example.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=Shurl&algorithm=SHA1&digits=6&period=30"
+
+// referenceSymbols are in testdata/<name>.txt, one row per line with # for dark modules. They
+// were made with rsc.io/qr/coding from the same text, version, level M and mask, so a wrong
+// codeword, ECC byte, mask or format bit shows up as a differing module.
+var referenceSymbols = []struct {
+	name    string
+	version int
+	mask    int
+	text    string
+}{
+	{"v1-mask0", 1, 0, "Shurl"},
+	{"v1-mask1", 1, 1, "Shurl"},
+	{"v1-mask2", 1, 2, "Shurl"},
+	{"v1-mask3", 1, 3, "Shurl"},
+	{"v1-mask4", 1, 4, "Shurl"},
+	{"v1-mask5", 1, 5, "Shurl"},
+	{"v1-mask6", 1, 6, "Shurl"},
+	{"v1-mask7", 1, 7, "Shurl"},
+	{"v2-mask3", 2, 3, "https://shurl.io/abc123"},
+	// Two blocks
+	{"v5-mask5", 5, 5, "https://shurl.io/s/3f9a0c1d2e4b5a6978"},
+	// Version information and blocks of two lengths
+	{"v8-mask6", 8, 6, otpauthURL},
+	{"v10-mask1", 10, 1, otpauthURL + "&image=https%3A%2F%2Fshurl.io%2Flogo.png"},
+	{"v15-mask4", 15, 4, strings.Repeat("https://shurl.io/", 20)},
+	// 16 bit character count and many blocks
+	{"v27-mask7", 27, 7, strings.Repeat("The quick brown fox jumps over the lazy dog. ", 16)},
+}
+
+// rows renders the symbol the way the reference files store it.
+func rows(code *Code) []string {
+	lines := make([]string, code.Size)
+	for y := range lines {
+		var line strings.Builder
+		for x := 0; x < code.Size; x++ {
+			if code.Dark(x, y) {
+				line.WriteByte('#')
+			} else {
+				line.WriteByte('.')
+			}
+		}
+		lines[y] = line.String()
+	}
+	return lines
+}
+
+func TestEncodeMatchesReference(t *testing.T) {
+	for _, symbol := range referenceSymbols {
+		golden, err := os.ReadFile(filepath.Join("testdata", symbol.name+".txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Split(strings.TrimSpace(string(golden)), "\n")
+
+		got := rows(encode([]byte(symbol.text), symbol.version, symbol.mask))
+		if len(got) != len(want) {
+			t.Errorf("%s: %d rows, want %d", symbol.name, len(got), len(want))
+			continue
+		}
+		for y := range want {
+			if got[y] != want[y] {
+				t.Errorf("%s: row %d\n got %s\nwant %s", symbol.name, y, got[y], want[y])
+				break
+			}
+		}
+	}
+}
+
+func TestEncodeChoosesLowestPenaltyMask(t *testing.T) {
+	for _, text := range []string{"Shurl", "https://shurl.io/abc123", otpauthURL} {
+		code, err := Encode(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		version := (code.Size - 17) / 4
+
+		bestMask, minPenalty := -1, 0
+		for mask := 0; mask < 8; mask++ {
+			if penalty := encode([]byte(text), version, mask).penalty(); bestMask < 0 || penalty < minPenalty {
+				bestMask, minPenalty = mask, penalty
+			}
+		}
+
+		want := rows(encode([]byte(text), version, bestMask))
+		if got := rows(code); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%q: Encode did not use mask %d, the one with the lowest penalty", text, bestMask)
+		}
+	}
+}
+
+func TestEncodePicksSmallestVersion(t *testing.T) {
+	// Byte mode capacities at level M: 14 for version 1, 26 for 2, 213 for 10 and 2331 for 40
+	tests := []struct {
+		length int
+		size   int
+	}{
+		{0, 21},
+		{14, 21},
+		{15, 25},
+		{26, 25},
+		{27, 29},
+		{213, 57},
+		{214, 61},
+		{2331, 177},
+	}
+
+	for _, tt := range tests {
+		code, err := Encode(strings.Repeat("a", tt.length))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", tt.length, err)
+		}
+		if code.Size != tt.size {
+			t.Errorf("%d bytes: size %d, want %d", tt.length, code.Size, tt.size)
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("a", 2332)); err != ErrTooLong {
+		t.Errorf("2332 bytes: error %v, want ErrTooLong", err)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	code, err := Encode(otpauthURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const scale = 3
+	data, err := code.PNG(scale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	width := (code.Size + 2*QuietZone) * scale
+	if bounds := img.Bounds(); bounds.Dx() != width || bounds.Dy() != width {
+		t.Fatalf("image is %v, want %dx%d", bounds, width, width)
+	}
+
+	dark := func(x int, y int) bool {
+		r, _, _, _ := img.At(x, y).RGBA()
+		return r == 0
+	}
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			px, py := (x+QuietZone)*scale, (y+QuietZone)*scale
+			if dark(px, py) != code.Dark(x, y) || dark(px+scale-1, py+scale-1) != code.Dark(x, y) {
+				t.Fatalf("module %d,%d rendered wrong", x, y)
+			}
+		}
+	}
+	for i := 0; i < QuietZone*scale; i++ {
+		if dark(i, i) || dark(width-1-i, width-1-i) {
+			t.Fatal("quiet zone is not light")
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"net/url"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/mailer"
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+// dayLayout matches the day keys of the click rollups.
+const dayLayout = "2006-01-02"
+
+// topLinksLimit is how many of the busiest links a digest lists.
+const topLinksLimit = 5
+
+// ErrNothingToReport is returned by Send when the user has no links, so no email is sent.
+var ErrNothingToReport = errors.New("nothing to report")
+
+//go:embed templates/digest.txt
+var digestText string
+
+//go:embed templates/digest.html
+var digestHTML string
+
+var templateFuncs = map[string]interface{}{
+	"deref": func(value *float64) float64 { return *value },
+}
+
+var (
+	digestTextTemplate = texttemplate.Must(texttemplate.New("digest.txt").Funcs(templateFuncs).Parse(digestText))
+	digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(templateFuncs).Parse(digestHTML))
+)
+
+// Report summarises the performance of a user's links over one period.
+type Report struct {
+	Frequency   string    `json:"frequency"`
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+
+	// Clicks in this period and the one before it
+	Clicks         int `json:"clicks"`
+	PreviousClicks int `json:"previousClicks"`
+
+	// Percentage change in clicks from the previous period, nil when it had none
+	Growth *float64 `json:"growth"`
+
+	TotalLinks int `json:"totalLinks"`
+	NewLinks   int `json:"newLinks"`
+
+	TopLinks    []ReportLink `json:"topLinks"`
+	BrokenLinks []ReportLink `json:"brokenLinks"`
+}
+
+// ReportLink is a link listed in a report.
+type ReportLink struct {
+	ShortCode   string `json:"shortCode"`
+	ShortURL    string `json:"shortUrl"`
+	Title       string `json:"title"`
+	OriginalURL string `json:"originalUrl"`
+	Clicks      int    `json:"clicks"`
+	HealthError string `json:"healthError,omitempty"`
+}
+
+// Period returns the most recent complete period for a schedule: yesterday for daily digests,
+// last Monday to Sunday for weekly ones. Periods are in UTC.
+func Period(frequency string, now time.Time) (time.Time, time.Time) {
+	end := now.UTC().Truncate(24 * time.Hour)
+
+	if frequency == models.ReportWeekly {
+		// Weekdays count from Sunday; step back to this week's Monday
+		end = end.AddDate(0, 0, -((int(end.Weekday()) + 6) % 7))
+		return end.AddDate(0, 0, -7), end
+	}
+	return end.AddDate(0, 0, -1), end
+}
+
+// Due reports whether the user should receive a digest for the latest complete period.
+func Due(user models.User, now time.Time) bool {
+	if user.ReportFrequency != models.ReportDaily && user.ReportFrequency != models.ReportWeekly {
+		return false
+	}
+
+	_, end := Period(user.ReportFrequency, now)
+	return user.ReportLastSentAt == nil || user.ReportLastSentAt.Before(end)
+}
+
+// Build aggregates the user's link statistics for the latest complete period of the schedule.
+func Build(userID uint, frequency string, now time.Time) (Report, error) {
+	start, end := Period(frequency, now)
+	previousStart := start.Add(-end.Sub(start))
+
+	report := Report{
+		Frequency:   frequency,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		TopLinks:    []ReportLink{},
+		BrokenLinks: []ReportLink{},
+	}
+
+	var err error
+	if report.Clicks, err = sumClicks(userID, start, end); err != nil {
+		return report, err
+	}
+	if report.PreviousClicks, err = sumClicks(userID, previousStart, start); err != nil {
+		return report, err
+	}
+	if report.PreviousClicks > 0 {
+		growth := float64(report.Clicks-report.PreviousClicks) * 100 / float64(report.PreviousClicks)
+		report.Growth = &growth
+	}
+
+	var totalLinks, newLinks int64
+	if err := initializers.DB.Model(&models.Link{}).Where("user_id = ?", userID).Count(&totalLinks).Error; err != nil {
+		return report, err
+	}
+	err = initializers.DB.Model(&models.Link{}).
+		Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, start, end).
+		Count(&newLinks).Error
+	if err != nil {
+		return report, err
+	}
+	report.TotalLinks = int(totalLinks)
+	report.NewLinks = int(newLinks)
+
+	var topRows []struct {
+		models.Link
+		PeriodClicks int
+	}
+	err = initializers.DB.Table("link_click_days").
+		Select("links.*, SUM(link_click_days.clicks) AS period_clicks").
+		Joins("JOIN links ON links.id = link_click_days.link_id AND links.deleted_at IS NULL").
+		Where("links.user_id = ? AND link_click_days.day >= ? AND link_click_days.day < ?", userID, start.Format(dayLayout), end.Format(dayLayout)).
+		Group("links.id").
+		Order("period_clicks DESC").
+		Limit(topLinksLimit).
+		Scan(&topRows).Error
+	if err != nil {
+		return report, err
+	}
+
+	var broken []models.Link
+	err = initializers.DB.Where("user_id = ? AND health_status = ?", userID, models.HealthBroken).
+		Order("health_checked_at DESC").
+		Find(&broken).Error
+	if err != nil {
+		return report, err
+	}
+
+	hostnames, err := domainHostnames(userID)
+	if err != nil {
+		return report, err
+	}
+
+	for _, row := range topRows {
+		reportLink := toReportLink(row.Link, hostnames)
+		reportLink.Clicks = row.PeriodClicks
+		report.TopLinks = append(report.TopLinks, reportLink)
+	}
+	for _, link := range broken {
+		reportLink := toReportLink(link, hostnames)
+		reportLink.HealthError = link.HealthError
+		report.BrokenLinks = append(report.BrokenLinks, reportLink)
+	}
+
+	return report, nil
+}
+
+// Render produces the digest email for a report.
+func Render(user models.User, report Report) (mailer.Message, error) {
+	data := struct {
+		User   models.User
+		Report Report
+		Period string
+	}{
+		User:   user,
+		Report: report,
+		Period: describePeriod(report),
+	}
+
+	var text, html bytes.Buffer
+	if err := digestTextTemplate.Execute(&text, data); err != nil {
+		return mailer.Message{}, err
+	}
+	if err := digestHTMLTemplate.Execute(&html, data); err != nil {
+		return mailer.Message{}, err
+	}
+
+	return mailer.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Your %s Shurl report: %d clicks", report.Frequency, report.Clicks),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// Send builds, renders and emails the user's digest for the latest complete period of
+// the given schedule. Users without any links get no email and ErrNothingToReport.
+func Send(ctx context.Context, m mailer.Mailer, user models.User, frequency string, now time.Time) error {
+	report, err := Build(user.ID, frequency, now)
+	if err != nil {
+		return err
+	}
+	if report.TotalLinks == 0 {
+		return ErrNothingToReport
+	}
+
+	message, err := Render(user, report)
+	if err != nil {
+		return err
+	}
+
+	return m.Send(ctx, message)
+}
+
+// Helper function: Total clicks on the user's links between two UTC days
+func sumClicks(userID uint, start time.Time, end time.Time) (int, error) {
+	var clicks int
+	err := initializers.DB.Table("link_click_days").
+		Select("COALESCE(SUM(link_click_days.clicks), 0)").
+		Joins("JOIN links ON links.id = link_click_days.link_id AND links.deleted_at IS NULL").
+		Where("links.user_id = ? AND link_click_days.day >= ? AND link_click_days.day < ?", userID, start.Format(dayLayout), end.Format(dayLayout)).
+		Scan(&clicks).Error
+	return clicks, err
+}
+
+// Helper function: Map the user's domain IDs to hostnames for building short URLs
+func domainHostnames(userID uint) (map[uint]string, error) {
+	var domains []models.Domain
+	if err := initializers.DB.Where("user_id = ?", userID).Find(&domains).Error; err != nil {
+		return nil, err
+	}
+
+	hostnames := map[uint]string{}
+	for _, domain := range domains {
+		hostnames[domain.ID] = domain.Hostname
+	}
+	return hostnames, nil
+}
+
+func toReportLink(link models.Link, hostnames map[uint]string) ReportLink {
+	baseURL := initializers.PublicBaseURLs()[0]
+	if hostname, ok := hostnames[link.DomainID]; ok {
+		baseURL = "https://" + hostname
+	}
+
+	return ReportLink{
+		ShortCode:   link.ShortCode,
+		ShortURL:    baseURL + "/" + url.PathEscape(link.ShortCode),
+		Title:       link.Title,
+		OriginalURL: link.OriginalURL,
+	}
+}
+
+func describePeriod(report Report) string {
+	last := report.PeriodEnd.AddDate(0, 0, -1)
+	if report.Frequency == models.ReportDaily {
+		return last.Format("Monday 2 January 2006")
+	}
+	return report.PeriodStart.Format("2 January") + " to " + last.Format("2 January 2006")
+}
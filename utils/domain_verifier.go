@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DomainTXTPrefix is the subdomain that holds the DNS TXT verification record.
+const DomainTXTPrefix = "_shurl-verify"
+
+// DomainWellKnownPath is where the HTTP verification token is served.
+const DomainWellKnownPath = "/.well-known/shurl-verification"
+
+// ErrDomainNotVerified is returned when neither the DNS nor the HTTP check finds the token.
+var ErrDomainNotVerified = errors.New("verification token not found via DNS TXT record or HTTP well-known file")
+
+// DomainVerifier proves that whoever registered a hostname controls it.
+type DomainVerifier interface {
+	Verify(ctx context.Context, hostname string, token string) error
+}
+
+// TXTResolver looks up DNS TXT records. *net.Resolver satisfies it.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// TokenDomainVerifier accepts a domain when the token is published either as a
+// TXT record on _shurl-verify.<hostname> or at http://<hostname>/.well-known/shurl-verification.
+type TokenDomainVerifier struct {
+	Resolver   TXTResolver
+	HTTPClient *http.Client
+}
+
+// NewDomainVerifier returns a verifier backed by the system resolver and a short-timeout HTTP client.
+// Users choose the hostname, so the client only connects to public addresses.
+func NewDomainVerifier() *TokenDomainVerifier {
+	return &TokenDomainVerifier{
+		Resolver:   net.DefaultResolver,
+		HTTPClient: NewPublicHTTPClient(5 * time.Second),
+	}
+}
+
+// DomainTXTValue is the exact TXT record value expected for a token.
+func DomainTXTValue(token string) string {
+	return "shurl-verify=" + token
+}
+
+// Verify checks DNS first and falls back to HTTP.
+func (v *TokenDomainVerifier) Verify(ctx context.Context, hostname string, token string) error {
+	if v.verifyTXT(ctx, hostname, token) {
+		return nil
+	}
+	if v.verifyHTTP(ctx, hostname, token) {
+		return nil
+	}
+	return ErrDomainNotVerified
+}
+
+func (v *TokenDomainVerifier) verifyTXT(ctx context.Context, hostname string, token string) bool {
+	if v.Resolver == nil {
+		return false
+	}
+
+	records, err := v.Resolver.LookupTXT(ctx, DomainTXTPrefix+"."+hostname)
+	if err != nil {
+		return false
+	}
+
+	for _, record := range records {
+		if strings.TrimSpace(record) == DomainTXTValue(token) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *TokenDomainVerifier) verifyHTTP(ctx context.Context, hostname string, token string) bool {
+	if v.HTTPClient == nil {
+		return false
+	}
+
+	url := fmt.Sprintf("http://%s%s", hostname, DomainWellKnownPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return false
+	}
+
+	return strings.TrimSpace(string(body)) == token
+}
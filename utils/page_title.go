@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxTitleBodyBytes caps how much of a page is read while looking for its <title>.
+const maxTitleBodyBytes = 512 * 1024
+
+// titleFetchTimeout bounds the whole request, including redirects.
+const titleFetchTimeout = 5 * time.Second
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// titleClient only connects to public addresses, as the URL comes from the user
+var titleClient = NewPublicHTTPClient(titleFetchTimeout)
+
+// FetchPageTitle downloads an HTML page and returns the text of its <title> element.
+func FetchPageTitle(ctx context.Context, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "Shurl/1.0 (+https://github.com/olujimiAdebakin/Shurl)")
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := titleClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", errors.New("unexpected status: " + resp.Status)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "html") {
+		return "", errors.New("not an HTML page: " + contentType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTitleBodyBytes))
+	if err != nil {
+		return "", err
+	}
+
+	match := titlePattern.FindSubmatch(body)
+	if match == nil {
+		return "", errors.New("no title found")
+	}
+
+	// Collapse whitespace and decode entities such as &amp;
+	title := strings.Join(strings.Fields(html.UnescapeString(string(match[1]))), " ")
+	if title == "" {
+		return "", errors.New("empty title")
+	}
+
+	return title, nil
+}
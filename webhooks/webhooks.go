@@ -0,0 +1,116 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/olujimiAdebakin/Shurl/initializers"
+	"github.com/olujimiAdebakin/Shurl/models"
+)
+
+// Event types users can subscribe to
+const (
+	EventLinkCreated = "link.created"
+	EventLinkUpdated = "link.updated"
+	EventLinkDeleted = "link.deleted"
+	EventLinkExpired = "link.expired"
+	EventLinkClicked = "link.clicked"
+)
+
+// Events lists every supported event type.
+var Events = []string{
+	EventLinkCreated,
+	EventLinkUpdated,
+	EventLinkDeleted,
+	EventLinkExpired,
+	EventLinkClicked,
+}
+
+// Headers sent with every delivery
+const (
+	SignatureHeader = "X-Shurl-Signature"
+	EventHeader     = "X-Shurl-Event"
+	DeliveryHeader  = "X-Shurl-Delivery"
+)
+
+// Payload is the JSON envelope POSTed to webhook endpoints.
+type Payload struct {
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// IsValidEvent reports whether event is a supported event type.
+func IsValidEvent(event string) bool {
+	for _, known := range Events {
+		if known == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the signature header value for a payload: "sha256=" followed by the
+// hex-encoded HMAC-SHA256 of the raw body using the webhook secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Subscribes reports whether a webhook is subscribed to an event.
+func Subscribes(webhook models.Webhook, event string) bool {
+	for _, subscribed := range strings.Split(webhook.Events, ",") {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Enqueue queues a delivery of the event to each of the user's active webhooks subscribed to it.
+// Deliveries are sent by the background dispatcher, so this never blocks on the endpoints.
+// Failures are logged rather than returned: a webhook problem must not fail the API request.
+func Enqueue(userID uint, event string, data interface{}) {
+	var hooks []models.Webhook
+	if err := initializers.DB.Where("user_id = ? AND active = ?", userID, true).Find(&hooks).Error; err != nil {
+		log.Printf("Failed to load webhooks for user %d: %v", userID, err)
+		return
+	}
+
+	var body []byte
+	for _, hook := range hooks {
+		if !Subscribes(hook, event) {
+			continue
+		}
+
+		if body == nil {
+			var err error
+			body, err = json.Marshal(Payload{
+				Event:     event,
+				CreatedAt: time.Now().UTC(),
+				Data:      data,
+			})
+			if err != nil {
+				log.Printf("Failed to encode %s webhook payload: %v", event, err)
+				return
+			}
+		}
+
+		delivery := models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			Event:         event,
+			Payload:       string(body),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		}
+		if err := initializers.DB.Create(&delivery).Error; err != nil {
+			log.Printf("Failed to queue %s delivery for webhook %d: %v", event, hook.ID, err)
+		}
+	}
+}